@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Mukilan-T/laabhum-broker-adapter-go/internal/adapter"
+	"github.com/Mukilan-T/laabhum-broker-adapter-go/internal/broker"
 	"github.com/Mukilan-T/laabhum-broker-adapter-go/internal/config"
 	"github.com/Mukilan-T/laabhum-broker-adapter-go/internal/metrics"
 	"github.com/gorilla/mux"
@@ -32,7 +33,8 @@ import (
 		// adapter.SetupRoutes(router, kafkaProducer, prometheus)
 	
 		// Initialize position handler
-		positionHandler := adapter.NewPositionHandler(brokers, "your-topic-name")  // Update to use correct function
+		brokerClient := broker.NewBrokerClient(cfg.BrokerConfig.APIBaseURL, cfg.BrokerConfig.APIKey)
+		positionHandler := adapter.NewPositionHandler(brokers, "your-topic-name", brokerClient)
 	
 		// Setup additional routes
 		router.HandleFunc("/positions", positionHandler.GetPositions).Methods("GET")
@@ -72,7 +72,8 @@ func New(cfg *config.Config, metricsRegistry *metrics.Registry) (*Adapter, error
 
 	// Create specific handlers
 	adapter.orderHandler = NewOrderHandler(*adapter.brokerClient, adapter.kafkaProducer)
-	adapter.posHandler = NewPositionHandler(cfg.KafkaConfig.Brokers, cfg.KafkaConfig.Topic)
+	adapter.posHandler = NewPositionHandler(cfg.KafkaConfig.Brokers, cfg.KafkaConfig.Topic,
+		broker.NewBrokerClient(cfg.BrokerConfig.APIBaseURL, cfg.BrokerConfig.APIKey))
 	adapter.marketDataHandler = NewMarketDataHandler()
 
 	return adapter, nil
@@ -229,8 +230,14 @@ func (a *Adapter) GetPositions(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// StreamMarketData handles real-time market data streaming via WebSocket.
+// StreamMarketData handles real-time market data streaming via WebSocket for
+// the symbol named in the "symbol" query parameter.
 func (a *Adapter) StreamMarketData(w http.ResponseWriter, r *http.Request) {
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		http.Error(w, "symbol is required", http.StatusBadRequest)
+		return
+	}
 	upgrader := websocket.Upgrader{}
 
 	// Upgrade HTTP connection to WebSocket
@@ -242,11 +249,12 @@ func (a *Adapter) StreamMarketData(w http.ResponseWriter, r *http.Request) {
 	defer conn.Close()
 
 	// Subscribe to market data
-	marketDataCh, err := a.brokerClient.Subscribe("AAPL")
+	marketDataCh, err := a.brokerClient.Subscribe(symbol)
 	if err != nil {
 		http.Error(w, "failed to subscribe to market data", http.StatusInternalServerError)
 		return
 	}
+	defer a.brokerClient.Unsubscribe(symbol)
 
 	// Stream market data to WebSocket client
 	for marketData := range marketDataCh {
@@ -2,38 +2,61 @@ package adapter
 
 import (
 	"encoding/json"
+	"io"
 	"log"
 	"net/http"
 
+	"github.com/Mukilan-T/laabhum-broker-adapter-go/internal/broker"
 	kafka "github.com/Mukilan-T/laabhum-broker-adapter-go/pkg/kafka/producer"
+	"github.com/Mukilan-T/laabhum-broker-adapter-go/pkg/sdk"
 	"github.com/Mukilan-T/laabhum-broker-adapter-go/pkg/utils"
 )
 
 type PositionHandler struct {
-    kafkaProducer *kafka.KafkaProducer
+	kafkaProducer *kafka.KafkaProducer
+	brokerClient  *broker.BrokerClient
 }
 
-func NewPositionHandler(brokers []string, topic string) *PositionHandler {
-    producer := kafka.NewProducer(brokers, topic)
-    if producer == nil {
-        // Handle error, e.g., log and exit or return nil
-        log.Fatalf("Failed to create Kafka producer")
-    }
-    return &PositionHandler{kafkaProducer: producer}
+func NewPositionHandler(brokers []string, topic string, brokerClient *broker.BrokerClient) *PositionHandler {
+	producer := kafka.NewProducer(brokers, topic)
+	if producer == nil {
+		// Handle error, e.g., log and exit or return nil
+		log.Fatalf("Failed to create Kafka producer")
+	}
+	return &PositionHandler{kafkaProducer: producer, brokerClient: brokerClient}
 }
 
 func (h *PositionHandler) GetPositions(w http.ResponseWriter, r *http.Request) {
-    positions := map[string]interface{}{
-        "positions": []map[string]interface{}{{"symbol": "AAPL", "qty": 10}},
-    }
-    utils.RespondWithJSON(w, http.StatusOK, positions)
+	positions := map[string]interface{}{
+		"positions": []map[string]interface{}{{"symbol": "AAPL", "qty": 10}},
+	}
+	utils.RespondWithJSON(w, http.StatusOK, positions)
 }
 
+// ConvertPosition forwards a product type conversion to the broker and
+// answers 200 only once the broker has accepted it.
 func (h *PositionHandler) ConvertPosition(w http.ResponseWriter, r *http.Request) {
-    var conversionRequest map[string]interface{}
-    if err := json.NewDecoder(r.Body).Decode(&conversionRequest); err != nil {
-        utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
-        return
-    }
-    utils.RespondWithJSON(w, http.StatusOK, map[string]string{"status": "position converted"})
+	var conversion sdk.PositionConversion
+	if err := json.NewDecoder(r.Body).Decode(&conversion); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if err := conversion.Validate(); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.brokerClient.ConvertPosition(broker.MapToBrokerConversion(conversion))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadGateway, "Broker unreachable: "+err.Error())
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		log.Printf("Broker rejected conversion of %s: %d %s", conversion.Symbol, resp.StatusCode, message)
+		utils.RespondWithError(w, http.StatusBadGateway, "Broker rejected conversion: "+string(message))
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"status": "position converted"})
 }
@@ -63,6 +63,18 @@ func (client *BrokerClient) PlaceOrder(orderPayload map[string]interface{}) (*ht
 	return http.DefaultClient.Do(req)
 }
 
+// ConvertPosition asks the broker to move a position to another product type.
+func (client *BrokerClient) ConvertPosition(conversionPayload map[string]interface{}) (*http.Response, error) {
+	payload, _ := json.Marshal(conversionPayload)
+	req, err := http.NewRequest("PUT", client.BaseURL+"/portfolio/positions", bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+client.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	return http.DefaultClient.Do(req)
+}
+
 // NewClient initializes a new WebSocket client with broker client.
 func NewClient(cfg *config.BrokerConfig) (*Client, error) {
 	wsClient, _, err := websocket.DefaultDialer.Dial(cfg.WebSocketURL, nil)
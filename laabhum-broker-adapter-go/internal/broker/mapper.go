@@ -34,3 +34,18 @@ func MapPositionResponse(brokerResponse map[string]interface{}) map[string]inter
 		"price":  brokerResponse["price"],
 	}
 }
+
+func MapToBrokerConversion(conversion sdk.PositionConversion) map[string]interface{} {
+	positionType := "day"
+	if conversion.FromProduct != sdk.ProductMIS {
+		positionType = "overnight"
+	}
+	return map[string]interface{}{
+		"symbol":           conversion.Symbol,
+		"qty":              conversion.Quantity,
+		"transaction_type": conversion.Side,
+		"position_type":    positionType,
+		"old_product":      conversion.FromProduct,
+		"new_product":      conversion.ToProduct,
+	}
+}
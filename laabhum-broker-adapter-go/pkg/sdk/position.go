@@ -3,9 +3,48 @@ package sdk
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 )
 
+type ProductType string
+
+const (
+	ProductMIS  ProductType = "MIS"  // Intraday, squared off by the broker at day end
+	ProductCNC  ProductType = "CNC"  // Delivery
+	ProductNRML ProductType = "NRML" // Carry-forward derivatives
+)
+
+// PositionConversion moves Quantity of a position from one product type to
+// another. Side is the side of the position: "buy" for long, "sell" for short.
+type PositionConversion struct {
+	Symbol      string      `json:"symbol"`
+	Quantity    int         `json:"quantity"`
+	FromProduct ProductType `json:"from_product"`
+	ToProduct   ProductType `json:"to_product"`
+	Side        string      `json:"side"`
+}
+
+// Validate checks that the conversion is well formed.
+func (c PositionConversion) Validate() error {
+	valid := func(p ProductType) bool {
+		return p == ProductMIS || p == ProductCNC || p == ProductNRML
+	}
+	switch {
+	case c.Symbol == "":
+		return fmt.Errorf("symbol is required")
+	case c.Quantity <= 0:
+		return fmt.Errorf("quantity must be positive")
+	case !valid(c.FromProduct) || !valid(c.ToProduct):
+		return fmt.Errorf("unknown product type conversion %q -> %q", c.FromProduct, c.ToProduct)
+	case c.FromProduct == c.ToProduct:
+		return fmt.Errorf("from_product and to_product must differ")
+	case c.Side != "buy" && c.Side != "sell":
+		return fmt.Errorf("side must be 'buy' or 'sell'")
+	}
+	return nil
+}
+
 func GetPositions(baseURL string) ([]map[string]interface{}, error) {
 	req, err := http.NewRequest("GET", baseURL+"/positions", nil)
 	if err != nil {
@@ -26,13 +65,25 @@ func GetPositions(baseURL string) ([]map[string]interface{}, error) {
 	return positions, nil
 }
 
-func ConvertPosition(baseURL string, conversionPayload map[string]interface{}) error {
-	payload, _ := json.Marshal(conversionPayload)
-	req, err := http.NewRequest("POST", baseURL+"/position/convert", bytes.NewBuffer(payload))
+// ConvertPosition asks the adapter at baseURL to convert a position.
+func ConvertPosition(baseURL string, conversion PositionConversion) error {
+	payload, err := json.Marshal(conversion)
 	if err != nil {
 		return err
 	}
+	req, err := http.NewRequest("POST", baseURL+"/convert_position", bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
 
-	_, err = http.DefaultClient.Do(req)
-	return err
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("convert position failed with status %d", resp.StatusCode)
+	}
+	return nil
 }
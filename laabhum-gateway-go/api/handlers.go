@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/Mukilan-T/laabhum-gateway-go/config"
+	"github.com/Mukilan-T/laabhum-gateway-go/internal/auth"
 	"github.com/Mukilan-T/laabhum-gateway-go/internal/oms"
 	"github.com/Mukilan-T/laabhum-gateway-go/pkg/logger"
 	"github.com/gin-gonic/gin"
@@ -27,6 +28,11 @@ func NewHandlers(cfg *config.Config, logger *logger.Logger, omsClient *oms.Clien
 		omsClient: omsClient,
 	}
 }
+
+// as returns handlers whose OMS client acts for the authenticated caller of r.
+func (h *Handlers) as(r *http.Request) *Handlers {
+	return NewHandlers(h.cfg, h.logger, h.omsClient.WithCaller(auth.CallerFrom(r)))
+}
 func (h *Handlers) CreatePositionOrder(c *gin.Context) {
 	body, err := ioutil.ReadAll(c.Request.Body)
 	if err != nil {
@@ -165,43 +171,55 @@ func (h *Handlers) ExecuteChildOrder(c *gin.Context) {
 	c.Data(http.StatusOK, "application/json", response)
 }
 
+// GetOrders returns one page of orders, filtered, sorted and paged by the
+// same query parameters as the OMS. The cursor of the next page is returned
+// in the X-Next-Cursor header.
 func (h *Handlers) GetOrders(c *gin.Context) {
-	response, err := h.omsClient.GetOrders()
+	query, err := oms.ParseOrderQuery(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, err := h.omsClient.GetOrders(query)
 	if err != nil {
 		h.logger.Errorf("Failed to get orders: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get orders"})
 		return
 	}
 
-	c.Data(http.StatusOK, "application/json", response)
+	if page.NextCursor != "" {
+		c.Header("X-Next-Cursor", page.NextCursor)
+	}
+	c.Data(http.StatusOK, "application/json", page.Orders)
 }
 
 // SetupRoutes sets up the routes for the API
 func SetupRoutes(cfg *config.Config, logger *logger.Logger, omsClient *oms.Client) *mux.Router {
 	router := mux.NewRouter()
+	router.Use(auth.Authenticate([]byte(cfg.Auth.SessionSecret)))
 	h := NewHandlers(cfg, logger, omsClient)
 
 	router.Handle("/orders", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		c, _ := gin.CreateTestContext(w)
 		c.Request = r
-		h.CreateOrder(c)
+		h.as(r).CreateOrder(c)
 	})).Methods(http.MethodPost)
 	router.Handle("/scalper/orders", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		c, _ := gin.CreateTestContext(w)
 		c.Request = r
-		h.CreateScalperOrder(c)
+		h.as(r).CreateScalperOrder(c)
 	})).Methods(http.MethodPost)
 	router.Handle("/orders", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		c, _ := gin.CreateTestContext(w)
 		c.Request = r
-		h.GetOrders(c)
+		h.as(r).GetOrders(c)
 	})).Methods(http.MethodGet)
 
 	// Additional routes for child orders and trades
 	router.Handle("/oms/child/{parentID}/{childID}/execute", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		c, _ := gin.CreateTestContext(w)
 		c.Request = r
-		h.ExecuteChildOrder(c)
+		h.as(r).ExecuteChildOrder(c)
 	})).Methods(http.MethodPost)
 
 	return router
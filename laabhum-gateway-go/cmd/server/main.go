@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -17,18 +16,6 @@ import (
 	"github.com/Mukilan-T/laabhum-gateway-go/pkg/logger"
 )
 
-type Order struct {
-	ID          string  `json:"id"`
-	Symbol      string  `json:"symbol"`
-	Quantity    int     `json:"quantity"`
-	Price       float64 `json:"price"`
-	Side        string  `json:"side"`   // "buy" or "sell"
-	Status      string  `json:"status"`
-	CreatedAt   int64   `json:"created_at"` // Optional, for tracking creation time
-	Description string  `json:"description,omitempty"` // Optional, use omitempty if not always needed
-}
-
-
 func main() {
 	cfg := config.LoadConfig()
 	if cfg == nil {
@@ -46,21 +33,10 @@ func main() {
 	if omsClient == nil {
 		stdLogger.Fatalf("Failed to create OMS client")
 	}
-
-	// Log orders 
-	ordersData, err := omsClient.GetOrders()
-	if err != nil {
-		stdLogger.Fatalf("Failed to get orders: %v", err)
-	}
-
-	var orders []Order
-	if err := json.Unmarshal(ordersData, &orders); err != nil {
-		stdLogger.Fatalf("Failed to unmarshal orders: %v", err)
-	}
-
-	for _, order := range orders {
-		stdLogger.Printf("Order ID: %s, Status: %s", order.ID, order.Status)
+	if cfg.Oms.CallerSecret == "" || cfg.Auth.SessionSecret == "" {
+		stdLogger.Fatalf("oms.caller_secret and auth.session_secret are required")
 	}
+	omsClient.CallerSecret = []byte(cfg.Oms.CallerSecret)
 
 	// Initialize strategy builder
 
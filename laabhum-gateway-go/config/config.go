@@ -11,13 +11,22 @@ import (
 type Config struct {
 	Oms struct {
 		BaseURL string `yaml:"baseURL"`
+		// CallerSecret signs the caller headers of every OMS request; the
+		// OMS is configured with the same secret
+		CallerSecret string `yaml:"caller_secret"`
 	} `yaml:"oms"`
+	Auth struct {
+		// SessionSecret verifies the HS256 session tokens clients send as
+		// "Authorization: Bearer"
+		SessionSecret string `yaml:"session_secret"`
+	} `yaml:"auth"`
 	LogLevel     string `yaml:"log_level"`
 	OMSAddress   string `yaml:"oms_address"`
 	ServerAddress string `yaml:"server_address"`
 }
 
-// LoadConfig loads configuration from a YAML file
+// LoadConfig loads configuration from a YAML file. GATEWAY_SESSION_SECRET and
+// OMS_CALLER_SECRET override the secrets so they can stay out of the file.
 func LoadConfig() *Config {
 	file, err := os.Open("config.yaml")
 	if err != nil {
@@ -31,6 +40,12 @@ func LoadConfig() *Config {
 	if err != nil {
 		log.Fatalf("Error decoding config file: %v", err)
 	}
+	if secret := os.Getenv("GATEWAY_SESSION_SECRET"); secret != "" {
+		cfg.Auth.SessionSecret = secret
+	}
+	if secret := os.Getenv("OMS_CALLER_SECRET"); secret != "" {
+		cfg.Oms.CallerSecret = secret
+	}
 
 	return &cfg
 }
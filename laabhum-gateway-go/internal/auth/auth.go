@@ -0,0 +1,131 @@
+// Package auth authenticates the sessions of gateway clients.
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-gateway-go/internal/oms"
+	"github.com/gorilla/mux"
+)
+
+// Roles a session can carry. Operators see and administer every account.
+const (
+	RoleTrader   = "trader"
+	RoleOperator = "operator"
+)
+
+// claims are the fields of a session token the gateway reads.
+type claims struct {
+	Subject   string `json:"sub"`
+	AccountID string `json:"account_id"`
+	Role      string `json:"role"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+type callerKey struct{}
+
+// Authenticate answers 401 to requests without a valid session: an
+// "Authorization: Bearer" HS256 JWT signed with secret, naming its user in
+// "sub" and carrying an "exp". A session without a "role" is a trader's, and a
+// trader's must name its "account_id". The caller of the session is stored in
+// the request for CallerFrom.
+func Authenticate(secret []byte) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				unauthorized(w)
+				return
+			}
+			caller, err := verify(secret, token, time.Now())
+			if err != nil {
+				unauthorized(w)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), callerKey{}, caller)))
+		})
+	}
+}
+
+// RequireOperator answers 403 to requests whose session is not an operator's.
+// It must run behind Authenticate.
+func RequireOperator(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if CallerFrom(r).Role != RoleOperator {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// CallerFrom returns the caller Authenticate stored in r.
+func CallerFrom(r *http.Request) oms.Caller {
+	caller, _ := r.Context().Value(callerKey{}).(oms.Caller)
+	return caller
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
+
+// verify checks the signature and expiry of token at now and returns the
+// caller it names.
+func verify(secret []byte, token string, now time.Time) (oms.Caller, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || len(secret) == 0 {
+		return oms.Caller{}, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return oms.Caller{}, errors.New("unsupported token algorithm")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return oms.Caller{}, err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return oms.Caller{}, errors.New("bad token signature")
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return oms.Caller{}, err
+	}
+	if c.ExpiresAt == 0 || !now.Before(time.Unix(c.ExpiresAt, 0)) {
+		return oms.Caller{}, errors.New("token expired")
+	}
+	if c.Role == "" {
+		c.Role = RoleTrader
+	}
+	switch {
+	case c.Subject == "":
+		return oms.Caller{}, errors.New("token names no user")
+	case c.Role != RoleTrader && c.Role != RoleOperator:
+		return oms.Caller{}, errors.New("unknown role " + c.Role)
+	case c.Role == RoleTrader && c.AccountID == "":
+		return oms.Caller{}, errors.New("trader token names no account")
+	}
+	return oms.Caller{AccountID: c.AccountID, UserID: c.Subject, Role: c.Role}, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -2,27 +2,89 @@ package oms
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Order represents an order in the system
 type Order struct {
-	ID          string  `json:"id"`
-	Symbol      string  `json:"symbol"`
-	Quantity    int     `json:"quantity"`
-	Price       float64 `json:"price"`
-	Side        string  `json:"side"`   // "buy" or "sell"
-	Status      string  `json:"status"`
-	CreatedAt   int64   `json:"created_at"` // Optional, for tracking creation time
-	Description string  `json:"description,omitempty"` // Optional, use omitempty if not always needed
+	ID            string  `json:"id"`
+	ClientOrderID string  `json:"client_order_id,omitempty"` // Resubmitting it returns the original order
+	Symbol        string  `json:"symbol"`
+	Quantity      int     `json:"quantity"`
+	Price         float64 `json:"price"`
+	Side          string  `json:"side"` // "buy" or "sell"
+	Status        string  `json:"status"`
+	CreatedAt     int64   `json:"created_at"`            // Optional, for tracking creation time
+	Description   string  `json:"description,omitempty"` // Optional, use omitempty if not always needed
 }
 
 // Client is the OMS client structure
 type Client struct {
 	BaseURL string
+	// Caller is who the client acts for; the OMS scopes every request to
+	// the caller's account. Bind it per request with WithCaller.
+	Caller Caller
+	// CallerSecret is shared with the OMS, which trusts the caller headers
+	// only when they are signed with it.
+	CallerSecret []byte
+}
+
+// Caller names the account, user and role requests to the OMS are made for.
+// Role is "trader" or "operator"; operators see every account.
+type Caller struct {
+	AccountID string
+	UserID    string
+	Role      string
+}
+
+// WithCaller returns a copy of c that acts for caller.
+func (c *Client) WithCaller(caller Caller) *Client {
+	bound := *c
+	bound.Caller = caller
+	return &bound
+}
+
+// httpClient returns an HTTP client that sends c.Caller with every request.
+func (c *Client) httpClient() *http.Client {
+	return &http.Client{Transport: callerTransport{caller: c.Caller, secret: c.CallerSecret}}
+}
+
+// callerTransport sets the caller headers of the OMS on each request and
+// signs them: X-Caller-Signature is the hex HMAC-SHA256 under secret of the
+// X-Caller-Timestamp and the three caller headers, one per line.
+type callerTransport struct {
+	caller Caller
+	secret []byte
+}
+
+func (t callerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for header, value := range map[string]string{
+		"X-Account-ID": t.caller.AccountID,
+		"X-User-ID":    t.caller.UserID,
+		"X-Role":       t.caller.Role,
+	} {
+		if value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(timestamp + "\n" + t.caller.AccountID + "\n" + t.caller.UserID + "\n" + t.caller.Role))
+	req.Header.Set("X-Caller-Timestamp", timestamp)
+	req.Header.Set("X-Caller-Signature", hex.EncodeToString(mac.Sum(nil)))
+	return http.DefaultTransport.RoundTrip(req)
 }
 
 type Position struct {
@@ -34,6 +96,8 @@ type Position struct {
 	Side         string  `json:"side"`         // Position side: "buy" or "sell"
 	Status       string  `json:"status"`       // Status of the position (e.g., open, closed)
 	Timestamp    int64   `json:"timestamp"`    // Timestamp of when the position was created
+	Product      string  `json:"product,omitempty"`    // Product type: MIS, CNC or NRML
+	ToProduct    string  `json:"to_product,omitempty"` // Target product type of a conversion request
 }
 
 type PositionOrder struct {
@@ -49,14 +113,62 @@ func convertPositionOrderToOrder(po PositionOrder) Order {
 		Quantity:    po.Quantity,
 	}
 }
+// ExecuteChildOrder executes a single child of a scalper order and returns
+// the OMS response body
 func (c *Client) ExecuteChildOrder(parentID, childID string) ([]byte, error) {
+	url := fmt.Sprintf("%s/oms/scalper/order/%s/%s/execute", c.BaseURL, parentID, childID)
+	resp, err := c.httpClient().Post(url, "application/json", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute child order: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to execute child order: %w", responseError(resp))
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// ExecuteAllChildTrades executes every working child of a scalper order
+func (c *Client) ExecuteAllChildTrades(parentID string) error {
+	return c.postCommand(fmt.Sprintf("/oms/scalper/order/%s/execute", parentID), "execute child trades")
+}
 
-    // Implement the logic to execute a child order
+// ExecuteSpecificChildTrade executes one child of a scalper order
+func (c *Client) ExecuteSpecificChildTrade(parentID, childID string) error {
+	_, err := c.ExecuteChildOrder(parentID, childID)
+	return err
+}
 
-    // This is a placeholder implementation
+// CancelSpecificChildOrder cancels one child of a scalper order
+func (c *Client) CancelSpecificChildOrder(parentID, orderID string) error {
+	return c.postCommand(fmt.Sprintf("/oms/scalper/order/%s/%s/cancel", parentID, orderID), "cancel child order")
+}
 
-    return []byte(`{"status": "success"}`), nil
+// CTCOrder moves the stop-loss of every profitable child of a scalper order
+// to its cost
+func (c *Client) CTCOrder(parentID string) error {
+	return c.postCommand(fmt.Sprintf("/oms/scalper/order/%s/ctc", parentID), "CTC order")
+}
 
+// CTCChildOrder moves the stop-loss of one child of a scalper order to its cost
+func (c *Client) CTCChildOrder(parentID, childID string) error {
+	return c.postCommand(fmt.Sprintf("/oms/scalper/order/%s/%s/ctc", parentID, childID), "CTC child order")
+}
+
+// postCommand sends a bodiless POST to an OMS command endpoint. The OMS
+// answers commands with 200 and a message, or 204.
+func (c *Client) postCommand(path, action string) error {
+	resp, err := c.httpClient().Post(c.BaseURL+path, "application/json", nil)
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to %s: %w", action, responseError(resp))
+	}
+	return nil
 }
 
 // CreatePositionOrder creates a new position order
@@ -67,34 +179,120 @@ func (c *Client) CreatePositionOrder(order Order) error {
 		return fmt.Errorf("failed to marshal position order: %w", err)
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(body))
+	resp, err := c.httpClient().Post(url, "application/json", bytes.NewBuffer(body))
 	if err != nil {
 		return fmt.Errorf("failed to create position order: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body) // Read response body for more details
-		return fmt.Errorf("failed to create position order, status code: %d, body: %s", resp.StatusCode, body)
+	if !succeeded(resp) {
+		return fmt.Errorf("failed to create position order: %w", responseError(resp))
 	}
 	return nil
 }
 
-// GetOrders retrieves all orders
-func (c *Client) GetOrders() ([]byte, error) {
+// OrderQuery filters, sorts and pages the orders returned by GetOrders.
+// Zero fields are left out of the query.
+type OrderQuery struct {
+	Statuses    []string // Any of them
+	Symbol      string
+	Side        string
+	AccountID   string // Only honored for operators
+	ParentID    string
+	CreatedFrom int64  // Inclusive, in Unix seconds
+	CreatedTo   int64  // Exclusive, in Unix seconds
+	Sort        string // created_at, updated_at, symbol or status; "-" prefix for descending
+	Limit       int
+	Cursor      string // NextCursor of the previous page
+}
+
+// ParseOrderQuery reads an OrderQuery from URL query parameters named as
+// the OMS names them.
+func ParseOrderQuery(values url.Values) (OrderQuery, error) {
+	query := OrderQuery{
+		Symbol:    values.Get("symbol"),
+		Side:      values.Get("side"),
+		AccountID: values.Get("account_id"),
+		ParentID:  values.Get("parent_id"),
+		Sort:      values.Get("sort"),
+		Cursor:    values.Get("cursor"),
+	}
+	for _, statuses := range values["status"] {
+		query.Statuses = append(query.Statuses, strings.Split(statuses, ",")...)
+	}
+	var err error
+	if v := values.Get("created_from"); v != "" {
+		if query.CreatedFrom, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return query, fmt.Errorf("invalid created_from: %w", err)
+		}
+	}
+	if v := values.Get("created_to"); v != "" {
+		if query.CreatedTo, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return query, fmt.Errorf("invalid created_to: %w", err)
+		}
+	}
+	if v := values.Get("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil {
+			return query, fmt.Errorf("invalid limit: %w", err)
+		}
+	}
+	return query, nil
+}
+
+// values encodes q as URL query parameters.
+func (q OrderQuery) values() url.Values {
+	values := url.Values{}
+	set := func(name, value string) {
+		if value != "" {
+			values.Set(name, value)
+		}
+	}
+	set("status", strings.Join(q.Statuses, ","))
+	set("symbol", q.Symbol)
+	set("side", q.Side)
+	set("account_id", q.AccountID)
+	set("parent_id", q.ParentID)
+	if q.CreatedFrom != 0 {
+		set("created_from", strconv.FormatInt(q.CreatedFrom, 10))
+	}
+	if q.CreatedTo != 0 {
+		set("created_to", strconv.FormatInt(q.CreatedTo, 10))
+	}
+	set("sort", q.Sort)
+	if q.Limit != 0 {
+		set("limit", strconv.Itoa(q.Limit))
+	}
+	set("cursor", q.Cursor)
+	return values
+}
+
+// OrderPage is one page of orders from GetOrders.
+type OrderPage struct {
+	Orders     json.RawMessage // JSON array of orders
+	NextCursor string          // Cursor of the next page; empty on the last page
+}
+
+// GetOrders retrieves one page of the orders matching query
+func (c *Client) GetOrders(query OrderQuery) (*OrderPage, error) {
 	url := c.BaseURL + "/orders"
-	resp, err := http.Get(url)
+	if values := query.values(); len(values) > 0 {
+		url += "?" + values.Encode()
+	}
+	resp, err := c.httpClient().Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get orders: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get orders, status code: %d, body: %s", resp.StatusCode, body)
+		return nil, fmt.Errorf("failed to get orders: %w", responseError(resp))
 	}
 
-	return ioutil.ReadAll(resp.Body)
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+	return &OrderPage{Orders: body, NextCursor: resp.Header.Get("X-Next-Cursor")}, nil
 }
 
 // Usage example function
@@ -120,14 +318,14 @@ func ExampleUsage(h *Handler) {
 
 func (c *Client) GetPositionsBySymbol(symbol string) ([]Position, error) {
 	url := fmt.Sprintf("%s/oms/positions?symbol=%s", c.BaseURL, symbol)
-	resp, err := http.Get(url)
+	resp, err := c.httpClient().Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get positions by symbol: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get positions by symbol, status code: %d", resp.StatusCode)
+	if !succeeded(resp) {
+		return nil, fmt.Errorf("failed to get positions by symbol: %w", responseError(resp))
 	}
 
 	var positions []Position
@@ -145,28 +343,28 @@ func NewClient(baseURL string) *Client {
 
 func (c *Client) ExecuteOrder(orderID string) error {
 	url := fmt.Sprintf("%s/oms/order/%s/execute", c.BaseURL, orderID)
-	resp, err := http.Post(url, "application/json", nil)
+	resp, err := c.httpClient().Post(url, "application/json", nil)
 	if err != nil {
 		return fmt.Errorf("failed to execute order: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to execute order, status code: %d", resp.StatusCode)
+	if !succeeded(resp) {
+		return fmt.Errorf("failed to execute order: %w", responseError(resp))
 	}
 	return nil
 }
 
 func (c *Client) CancelOrder(orderID string) error {
 	url := fmt.Sprintf("%s/oms/order/%s/cancel", c.BaseURL, orderID)
-	resp, err := http.Post(url, "application/json", nil)
+	resp, err := c.httpClient().Post(url, "application/json", nil)
 	if err != nil {
 		return fmt.Errorf("failed to cancel order: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to cancel order, status code: %d", resp.StatusCode)
+	if !succeeded(resp) {
+		return fmt.Errorf("failed to cancel order: %w", responseError(resp))
 	}
 	return nil
 }
@@ -177,15 +375,14 @@ func (c *Client) CreateOrder(order Order) ([]byte, error) {
     if err != nil {
         return nil, fmt.Errorf("failed to marshal order: %w", err)
     }
-    resp, err := http.Post(url, "application/json", bytes.NewBuffer(body))
+    resp, err := c.httpClient().Post(url, "application/json", bytes.NewBuffer(body))
     if err != nil {
         return nil, fmt.Errorf("failed to create order: %w", err)
     }
     defer resp.Body.Close()
 
-    if resp.StatusCode != http.StatusOK {
-        body, _ := ioutil.ReadAll(resp.Body)
-        return nil, fmt.Errorf("failed to create order, status code: %d, body: %s", resp.StatusCode, body)
+    if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+        return nil, fmt.Errorf("failed to create order: %w", responseError(resp))
     }
 
     return ioutil.ReadAll(resp.Body)
@@ -197,15 +394,14 @@ func (c *Client) CreateOrder(order Order) ([]byte, error) {
 // GetPositions retrieves current positions
 func (c *Client) GetPositions() ([]byte, error) {
 	url := c.BaseURL + "/oms/positions"
-	resp, err := http.Get(url)
+	resp, err := c.httpClient().Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get positions: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get positions, status code: %d, body: %s", resp.StatusCode, body)
+	if !succeeded(resp) {
+		return nil, fmt.Errorf("failed to get positions: %w", responseError(resp))
 	}
 
 	return ioutil.ReadAll(resp.Body)
@@ -214,29 +410,35 @@ func (c *Client) GetPositions() ([]byte, error) {
 // SyncPositions syncs positions
 func (c *Client) SyncPositions() error {
 	url := c.BaseURL + "/oms/positions/sync"
-	resp, err := http.Post(url, "application/json", nil)
+	resp, err := c.httpClient().Post(url, "application/json", nil)
 	if err != nil {
 		return fmt.Errorf("failed to sync positions: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to sync positions, status code: %d", resp.StatusCode)
+	if !succeeded(resp) {
+		return fmt.Errorf("failed to sync positions: %w", responseError(resp))
 	}
 	return nil
 }
 
 // ConvertPosition converts a position
-func (c *Client) ConvertPosition(positionID string) error {
+// ConvertPosition moves quantity of the position identified by positionID
+// ("<symbol>:<product>") to the toProduct product type
+func (c *Client) ConvertPosition(positionID, toProduct string, quantity int) error {
 	url := fmt.Sprintf("%s/oms/positions/%s/convert", c.BaseURL, positionID)
-	resp, err := http.Post(url, "application/json", nil)
+	body, err := json.Marshal(map[string]interface{}{"to_product": toProduct, "quantity": quantity})
+	if err != nil {
+		return fmt.Errorf("failed to marshal position conversion: %w", err)
+	}
+	resp, err := c.httpClient().Post(url, "application/json", bytes.NewBuffer(body))
 	if err != nil {
 		return fmt.Errorf("failed to convert position: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to convert position, status code: %d", resp.StatusCode)
+	if !succeeded(resp) {
+		return fmt.Errorf("failed to convert position: %w", responseError(resp))
 	}
 	return nil
 }
@@ -249,60 +451,49 @@ func (c *Client) DeletePositionOrder(positionID string) error {
 		return fmt.Errorf("failed to create delete request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to delete position order: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to delete position order, status code: %d", resp.StatusCode)
+	if !succeeded(resp) {
+		return fmt.Errorf("failed to delete position order: %w", responseError(resp))
 	}
 	return nil
 }
 
-// ExitAllTrades exits all trades
+// ExitAllTrades flattens every open position in the OMS
 func (c *Client) ExitAllTrades() error {
-	url := c.BaseURL + "/oms/scalper/exit/trade"
-	resp, err := http.Post(url, "application/json", nil)
-	if err != nil {
-		return fmt.Errorf("failed to exit all trades: %w", err)
-	}
-	defer resp.Body.Close()
+	return c.postCommand("/oms/scalper/exit/trade", "exit all trades")
+}
 
-	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to exit all trades, status code: %d", resp.StatusCode)
-	}
-	return nil
+// ExitAllChildTrades flattens every child of a scalper order
+func (c *Client) ExitAllChildTrades(parentID string) error {
+	return c.postCommand(fmt.Sprintf("/oms/scalper/trade/%s/exit", parentID), "exit child trades")
+}
+
+// ExitSpecificChildTrade flattens one child of a scalper order
+func (c *Client) ExitSpecificChildTrade(parentID, childID string) error {
+	return c.postCommand(fmt.Sprintf("/oms/scalper/trade/%s/%s/exit", parentID, childID), "exit child trade")
 }
 
 // CancelAllChildOrders cancels all child orders for a parent ID
 func (c *Client) CancelAllChildOrders(parentID string) error {
-	url := fmt.Sprintf("%s/oms/scalper/order/%s/cancel", c.BaseURL, parentID)
-	resp, err := http.Post(url, "application/json", nil)
-	if err != nil {
-		return fmt.Errorf("failed to cancel all child orders: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to cancel all child orders, status code: %d", resp.StatusCode)
-	}
-	return nil
+	return c.postCommand(fmt.Sprintf("/oms/scalper/order/%s/cancel", parentID), "cancel all child orders")
 }
 
 // GetTrades retrieves trades based on parentID
 func (c *Client) GetTrades(parentID string) ([]byte, error) {
 	url := fmt.Sprintf("%s/oms/scalper/trades/%s", c.BaseURL, parentID)
-	resp, err := http.Get(url)
+	resp, err := c.httpClient().Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get trades: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get trades, status code: %d, body: %s", resp.StatusCode, body)
+	if !succeeded(resp) {
+		return nil, fmt.Errorf("failed to get trades: %w", responseError(resp))
 	}
 
 	return ioutil.ReadAll(resp.Body)
@@ -316,14 +507,171 @@ func (c *Client) DeleteOrder(parentID string) error {
 		return fmt.Errorf("failed to create delete request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to delete order: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to delete order, status code: %d", resp.StatusCode)
+	if !succeeded(resp) {
+		return fmt.Errorf("failed to delete order: %w", responseError(resp))
 	}
 	return nil
 }
+
+// KillSwitchRequest engages or releases the kill switch of an account, or of
+// the whole OMS when AccountID is empty
+type KillSwitchRequest struct {
+	AccountID string `json:"account_id,omitempty"`
+	Actor     string `json:"actor,omitempty"` // Recorded by the OMS as the signed caller's user
+	Reason    string `json:"reason,omitempty"`
+	Flatten   bool   `json:"flatten,omitempty"` // Also exit open positions when engaging
+}
+
+// EngageKillSwitch blocks new orders in the requested scope and cancels its
+// resting orders. It returns the kill switch as reported by the OMS.
+func (c *Client) EngageKillSwitch(req KillSwitchRequest) ([]byte, error) {
+	return c.postKillSwitch("/oms/admin/kill-switch/engage", "engage kill switch", req)
+}
+
+// ReleaseKillSwitch lets orders in the requested scope through again
+func (c *Client) ReleaseKillSwitch(req KillSwitchRequest) ([]byte, error) {
+	return c.postKillSwitch("/oms/admin/kill-switch/release", "release kill switch", req)
+}
+
+// GetKillSwitches retrieves every kill switch with its audit trail
+func (c *Client) GetKillSwitches() ([]byte, error) {
+	resp, err := c.httpClient().Get(c.BaseURL + "/oms/admin/kill-switch")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kill switches: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !succeeded(resp) {
+		return nil, fmt.Errorf("failed to get kill switches: %w", responseError(resp))
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (c *Client) postKillSwitch(path, action string, req KillSwitchRequest) ([]byte, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal kill switch request: %w", err)
+	}
+	resp, err := c.httpClient().Post(c.BaseURL+path, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if !succeeded(resp) {
+		return nil, fmt.Errorf("failed to %s: %w", action, responseError(resp))
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// ErrVersionConflict is returned when the OMS rejects a change because the
+// order has changed since the version the change was made against
+var ErrVersionConflict = errors.New("order version conflict")
+
+// Error codes the OMS answers errors with
+const (
+	CodeNotFound          = "not_found"
+	CodeValidationFailed  = "validation_failed"
+	CodeInvalidTransition = "invalid_transition"
+	CodeConflict          = "conflict"
+	CodeRiskRejected      = "risk_rejected"
+	CodeAccessDenied      = "access_denied"
+	CodeUnauthorized      = "unauthorized"
+	CodeInternal          = "internal"
+)
+
+// Error is an error answer of the OMS. Code is one of the Code constants and
+// Risk names the rejecting check of a risk_rejected answer. Answers that are
+// not an OMS error body keep it as their Message and have no Code.
+type Error struct {
+	Status  int             `json:"-"`
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Risk    json.RawMessage `json:"risk,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("status code: %d, body: %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("%s (%d %s)", e.Message, e.Status, e.Code)
+}
+
+// succeeded reports whether the OMS answered resp with a 2xx status.
+func succeeded(resp *http.Response) bool {
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+// responseError reads the error answer resp of the OMS.
+func responseError(resp *http.Response) *Error {
+	body, _ := ioutil.ReadAll(resp.Body)
+	omsErr := &Error{Status: resp.StatusCode}
+	if err := json.Unmarshal(body, omsErr); err != nil || omsErr.Code == "" {
+		omsErr = &Error{Status: resp.StatusCode, Message: string(bytes.TrimSpace(body))}
+	}
+	return omsErr
+}
+
+// AmendRequest changes the quantity or prices of a working order. Version is
+// the order version the change is made against; fields left nil keep their
+// value.
+type AmendRequest struct {
+	Version      int      `json:"version"`
+	Quantity     *int     `json:"quantity,omitempty"`
+	Price        *float64 `json:"price,omitempty"`
+	TriggerPrice *float64 `json:"trigger_price,omitempty"`
+	StopLoss     *float64 `json:"stop_loss,omitempty"`
+	Actor        string   `json:"actor,omitempty"`
+}
+
+// ModifyOrder resizes a bracket order to req.Quantity. It returns the bracket
+// order as amended by the OMS.
+func (c *Client) ModifyOrder(parentID string, req AmendRequest) ([]byte, error) {
+	if req.Quantity == nil {
+		return nil, &Error{Status: http.StatusBadRequest, Code: CodeValidationFailed, Message: "a bracket order can only be resized; quantity is required"}
+	}
+	resize := struct {
+		Quantity int `json:"quantity"`
+		Version  int `json:"version"`
+	}{*req.Quantity, req.Version}
+	return c.patchAmendment(fmt.Sprintf("/oms/scalper/order/%s/modify", parentID), "modify order", resize)
+}
+
+// ModifyChildOrder amends a child of a scalper order. It returns the child as
+// amended by the OMS.
+func (c *Client) ModifyChildOrder(parentID, childID string, req AmendRequest) ([]byte, error) {
+	return c.patchAmendment(fmt.Sprintf("/oms/scalper/order/%s/%s/modify", parentID, childID), "modify child order", req)
+}
+
+func (c *Client) patchAmendment(path, action string, amendment interface{}) ([]byte, error) {
+	body, err := json.Marshal(amendment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal amendment: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPatch, c.BaseURL+path, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", action, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return ioutil.ReadAll(resp.Body)
+	}
+	omsErr := responseError(resp)
+	if omsErr.Code == CodeConflict {
+		// The version is all an amendment can conflict on.
+		return nil, fmt.Errorf("failed to %s: %w: %w", action, ErrVersionConflict, omsErr)
+	}
+	return nil, fmt.Errorf("failed to %s: %w", action, omsErr)
+}
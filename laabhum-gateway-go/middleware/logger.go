@@ -53,7 +53,7 @@ func Middleware(log *Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		log.Infof("%s | %3d | %13v | %15s | %s %s",
+		log.Infof("%3d | %13v | %15s | %s %s",
 			statusCode,
 			latency,
 			clientIP,
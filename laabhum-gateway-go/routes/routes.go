@@ -2,9 +2,11 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/Mukilan-T/laabhum-gateway-go/config"
+	"github.com/Mukilan-T/laabhum-gateway-go/internal/auth"
 	"github.com/Mukilan-T/laabhum-gateway-go/internal/oms"
 	"github.com/Mukilan-T/laabhum-gateway-go/pkg/logger"
 	"github.com/gorilla/mux"
@@ -12,39 +14,53 @@ import (
 
 func SetupRoutes(cfg *config.Config, logger *logger.Logger, omsClient *oms.Client) *mux.Router {
     router := mux.NewRouter()
+    router.Use(auth.Authenticate([]byte(cfg.Auth.SessionSecret)))
 
     // SCLAP Routes
-    router.HandleFunc("/oms/scalper/order", createScalperOrder(logger, omsClient)).Methods(http.MethodPost)
-    router.HandleFunc("/oms/scalper/order/{parentID}/execute", executeAllChildTrades(logger, omsClient)).Methods(http.MethodPost)
-    router.HandleFunc("/oms/scalper/order/{parentID}/{childID}/execute", executeSpecificChildTrade(logger, omsClient)).Methods(http.MethodPost)
-    router.HandleFunc("/oms/scalper/order/{parentID}/ctc", ctcOrder(logger, omsClient)).Methods(http.MethodPost)
-    router.HandleFunc("/oms/scalper/order/{parentID}/{childID}/ctc", ctcChildOrder(logger, omsClient)).Methods(http.MethodPost)
-    router.HandleFunc("/oms/scalper/order/{orderType}/{parentID}/modify", modifyOrder(logger, omsClient)).Methods(http.MethodPatch)
-    router.HandleFunc("/oms/scalper/order/{orderType}/{parentID}/{childID}/modify", modifyChildOrder(logger, omsClient)).Methods(http.MethodPatch)
-    router.HandleFunc("/oms/scalper/exit/trade", exitAllTrades(logger, omsClient)).Methods(http.MethodPost)
-    router.HandleFunc("/oms/scalper/trade/{parentID}/exit", exitAllChildTrades(logger, omsClient)).Methods(http.MethodPost)
-    router.HandleFunc("/oms/scalper/trade/{parentID}/{childID}/exit", exitSpecificChildTrade(logger, omsClient)).Methods(http.MethodPost)
-    router.HandleFunc("/oms/scalper/order/{parentID}/cancel", cancelAllChildOrders(logger, omsClient)).Methods(http.MethodPost)
-    router.HandleFunc("/oms/scalper/order/{parentID}/{orderId}/cancel", cancelSpecificChildOrder(logger, omsClient)).Methods(http.MethodPost)
-    router.HandleFunc("/oms/scalper/trades/{parentID}", getTrades(logger, omsClient)).Methods(http.MethodGet)
-    router.HandleFunc("/oms/scalper/order/{parentID}", deleteOrder(logger, omsClient)).Methods(http.MethodDelete)
+    router.HandleFunc("/oms/scalper/order", asCaller(createScalperOrder, logger, omsClient)).Methods(http.MethodPost)
+    router.HandleFunc("/oms/scalper/order/{parentID}/execute", asCaller(executeAllChildTrades, logger, omsClient)).Methods(http.MethodPost)
+    router.HandleFunc("/oms/scalper/order/{parentID}/{childID}/execute", asCaller(executeSpecificChildTrade, logger, omsClient)).Methods(http.MethodPost)
+    router.HandleFunc("/oms/scalper/order/{parentID}/ctc", asCaller(ctcOrder, logger, omsClient)).Methods(http.MethodPost)
+    router.HandleFunc("/oms/scalper/order/{parentID}/{childID}/ctc", asCaller(ctcChildOrder, logger, omsClient)).Methods(http.MethodPost)
+    router.HandleFunc("/oms/scalper/order/{parentID}/modify", asCaller(modifyOrder, logger, omsClient)).Methods(http.MethodPatch)
+    router.HandleFunc("/oms/scalper/order/{parentID}/{childID}/modify", asCaller(modifyChildOrder, logger, omsClient)).Methods(http.MethodPatch)
+    router.HandleFunc("/oms/scalper/exit/trade", asCaller(exitAllTrades, logger, omsClient)).Methods(http.MethodPost)
+    router.HandleFunc("/oms/scalper/trade/{parentID}/exit", asCaller(exitAllChildTrades, logger, omsClient)).Methods(http.MethodPost)
+    router.HandleFunc("/oms/scalper/trade/{parentID}/{childID}/exit", asCaller(exitSpecificChildTrade, logger, omsClient)).Methods(http.MethodPost)
+    router.HandleFunc("/oms/scalper/order/{parentID}/cancel", asCaller(cancelAllChildOrders, logger, omsClient)).Methods(http.MethodPost)
+    router.HandleFunc("/oms/scalper/order/{parentID}/{orderId}/cancel", asCaller(cancelSpecificChildOrder, logger, omsClient)).Methods(http.MethodPost)
+    router.HandleFunc("/oms/scalper/trades/{parentID}", asCaller(getTrades, logger, omsClient)).Methods(http.MethodGet)
+    router.HandleFunc("/oms/scalper/order/{parentID}", asCaller(deleteOrder, logger, omsClient)).Methods(http.MethodDelete)
 
     // ORDER Routes
-    router.HandleFunc("/oms/orders", getOrders(logger, omsClient)).Methods(http.MethodGet)
-    router.HandleFunc("/oms/order", createOrder(logger, omsClient)).Methods(http.MethodPut)
-    router.HandleFunc("/oms/order/execute", executeOrder(logger, omsClient)).Methods(http.MethodPost)
-    router.HandleFunc("/oms/order/cancel", cancelOrder(logger, omsClient)).Methods(http.MethodDelete)
+    router.HandleFunc("/oms/orders", asCaller(getOrders, logger, omsClient)).Methods(http.MethodGet)
+    router.HandleFunc("/oms/order", asCaller(createOrder, logger, omsClient)).Methods(http.MethodPut)
+    router.HandleFunc("/oms/order/execute", asCaller(executeOrder, logger, omsClient)).Methods(http.MethodPost)
+    router.HandleFunc("/oms/order/cancel", asCaller(cancelOrder, logger, omsClient)).Methods(http.MethodDelete)
 
     // POSITION Routes
-    router.HandleFunc("/oms/positions", getPositions(logger, omsClient)).Methods(http.MethodGet)
-    router.HandleFunc("/oms/position/sync", syncPosition(logger, omsClient)).Methods(http.MethodGet)
-    router.HandleFunc("/oms/position/convert", convertPosition(logger, omsClient)).Methods(http.MethodPut)
-    router.HandleFunc("/oms/position/order", createPositionOrder(logger, omsClient)).Methods(http.MethodPost)
-    router.HandleFunc("/oms/position/order", deletePositionOrder(logger, omsClient)).Methods(http.MethodDelete)
+    router.HandleFunc("/oms/positions", asCaller(getPositions, logger, omsClient)).Methods(http.MethodGet)
+    router.HandleFunc("/oms/position/sync", auth.RequireOperator(asCaller(syncPosition, logger, omsClient))).Methods(http.MethodGet)
+    router.HandleFunc("/oms/position/convert", asCaller(convertPosition, logger, omsClient)).Methods(http.MethodPut)
+    router.HandleFunc("/oms/position/order", asCaller(createPositionOrder, logger, omsClient)).Methods(http.MethodPost)
+    router.HandleFunc("/oms/position/order", asCaller(deletePositionOrder, logger, omsClient)).Methods(http.MethodDelete)
+
+    // Admin routes, for operators only
+    router.HandleFunc("/oms/admin/kill-switch", auth.RequireOperator(asCaller(getKillSwitches, logger, omsClient))).Methods(http.MethodGet)
+    router.HandleFunc("/oms/admin/kill-switch/engage", auth.RequireOperator(asCaller(engageKillSwitch, logger, omsClient))).Methods(http.MethodPost)
+    router.HandleFunc("/oms/admin/kill-switch/release", auth.RequireOperator(asCaller(releaseKillSwitch, logger, omsClient))).Methods(http.MethodPost)
 
     return router
 }
 
+// asCaller builds handler per request with an OMS client that acts for the
+// authenticated caller of the request.
+func asCaller(handler func(*logger.Logger, *oms.Client) http.HandlerFunc, logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        handler(logger, omsClient.WithCaller(auth.CallerFrom(r)))(w, r)
+    }
+}
+
 // SCLAP Handlers
 func createScalperOrder(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
@@ -58,7 +74,7 @@ func createScalperOrder(logger *logger.Logger, omsClient *oms.Client) http.Handl
         createdOrder, err := omsClient.CreateOrder(order)
         if err != nil {
             logger.Errorf("Failed to create scalper order: %v", err)
-            http.Error(w, "Failed to create order", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to create order")
             return
         }
 
@@ -76,7 +92,7 @@ func executeAllChildTrades(logger *logger.Logger, omsClient *oms.Client) http.Ha
         err := omsClient.ExecuteAllChildTrades(parentID)
         if err != nil {
             logger.Errorf("Failed to execute child trades for parent ID %s: %v", parentID, err)
-            http.Error(w, "Failed to execute child trades", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to execute child trades")
             return
         }
 
@@ -93,7 +109,7 @@ func executeSpecificChildTrade(logger *logger.Logger, omsClient *oms.Client) htt
         err := omsClient.ExecuteSpecificChildTrade(parentID, childID)
         if err != nil {
             logger.Errorf("Failed to execute child trade %s for parent ID %s: %v", childID, parentID, err)
-            http.Error(w, "Failed to execute specific child trade", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to execute specific child trade")
             return
         }
 
@@ -109,7 +125,7 @@ func ctcOrder(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc {
         err := omsClient.CTCOrder(parentID)
         if err != nil {
             logger.Errorf("Failed to CTC order for parent ID %s: %v", parentID, err)
-            http.Error(w, "Failed to CTC order", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to CTC order")
             return
         }
 
@@ -126,7 +142,7 @@ func ctcChildOrder(logger *logger.Logger, omsClient *oms.Client) http.HandlerFun
         err := omsClient.CTCChildOrder(parentID, childID)
         if err != nil {
             logger.Errorf("Failed to CTC child order %s for parent ID %s: %v", childID, parentID, err)
-            http.Error(w, "Failed to CTC child order", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to CTC child order")
             return
         }
 
@@ -137,49 +153,64 @@ func ctcChildOrder(logger *logger.Logger, omsClient *oms.Client) http.HandlerFun
 func modifyOrder(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         vars := mux.Vars(r)
-        orderType := vars["orderType"]
         parentID := vars["parentID"]
 
-        var order oms.Order
-        if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+        var req oms.AmendRequest
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
             logger.Errorf("Failed to decode modify order request: %v", err)
             http.Error(w, "Invalid request payload", http.StatusBadRequest)
             return
         }
 
-        err := omsClient.ModifyOrder(orderType, parentID, order)
+        order, err := omsClient.ModifyOrder(parentID, req)
         if err != nil {
             logger.Errorf("Failed to modify order for parent ID %s: %v", parentID, err)
-            http.Error(w, "Failed to modify order", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to modify order")
             return
         }
 
-        w.WriteHeader(http.StatusNoContent)
+        w.Header().Set("Content-Type", "application/json")
+        w.Write(order)
     }
 }
 
 func modifyChildOrder(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         vars := mux.Vars(r)
-        orderType := vars["orderType"]
         parentID := vars["parentID"]
+        childID := vars["childID"]
 
-        var order oms.Order
-        if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+        var req oms.AmendRequest
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
             logger.Errorf("Failed to decode modify child order request: %v", err)
             http.Error(w, "Invalid request payload", http.StatusBadRequest)
             return
         }
 
-        err := omsClient.ModifyChildOrder(orderType, parentID, order)
+        order, err := omsClient.ModifyChildOrder(parentID, childID, req)
         if err != nil {
-            logger.Errorf("Failed to modify child order for parent ID %s: %v", parentID, err)
-            http.Error(w, "Failed to modify child order", http.StatusInternalServerError)
+            logger.Errorf("Failed to modify child order %s for parent ID %s: %v", childID, parentID, err)
+            writeOMSError(w, err, "Failed to modify child order")
             return
         }
 
-        w.WriteHeader(http.StatusNoContent)
+        w.Header().Set("Content-Type", "application/json")
+        w.Write(order)
+    }
+}
+
+// writeOMSError passes an error answer of the OMS on with its status and
+// code, so the caller can tell a missing order, a rejected change or a version
+// conflict apart. Other failures are answered with 500 and message.
+func writeOMSError(w http.ResponseWriter, err error, message string) {
+    var omsErr *oms.Error
+    if errors.As(err, &omsErr) && omsErr.Code != "" {
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(omsErr.Status)
+        json.NewEncoder(w).Encode(omsErr)
+        return
     }
+    http.Error(w, message, http.StatusInternalServerError)
 }
 
 func exitAllTrades(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc {
@@ -187,7 +218,7 @@ func exitAllTrades(logger *logger.Logger, omsClient *oms.Client) http.HandlerFun
         err := omsClient.ExitAllTrades()
         if err != nil {
             logger.Errorf("Failed to exit all trades: %v", err)
-            http.Error(w, "Failed to exit all trades", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to exit all trades")
             return
         }
         w.WriteHeader(http.StatusNoContent)
@@ -202,7 +233,7 @@ func exitAllChildTrades(logger *logger.Logger, omsClient *oms.Client) http.Handl
         err := omsClient.ExitAllChildTrades(parentID)
         if err != nil {
             logger.Errorf("Failed to exit all child trades for parent ID %s: %v", parentID, err)
-            http.Error(w, "Failed to exit all child trades", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to exit all child trades")
             return
         }
 
@@ -219,7 +250,7 @@ func exitSpecificChildTrade(logger *logger.Logger, omsClient *oms.Client) http.H
         err := omsClient.ExitSpecificChildTrade(parentID, childID)
         if err != nil {
             logger.Errorf("Failed to exit specific child trade %s for parent ID %s: %v", childID, parentID, err)
-            http.Error(w, "Failed to exit specific child trade", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to exit specific child trade")
             return
         }
 
@@ -235,7 +266,7 @@ func cancelAllChildOrders(logger *logger.Logger, omsClient *oms.Client) http.Han
         err := omsClient.CancelAllChildOrders(parentID)
         if err != nil {
             logger.Errorf("Failed to cancel all child orders for parent ID %s: %v", parentID, err)
-            http.Error(w, "Failed to cancel all child orders", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to cancel all child orders")
             return
         }
 
@@ -252,7 +283,7 @@ func cancelSpecificChildOrder(logger *logger.Logger, omsClient *oms.Client) http
         err := omsClient.CancelSpecificChildOrder(parentID, orderId)
         if err != nil {
             logger.Errorf("Failed to cancel child order %s for parent ID %s: %v", orderId, parentID, err)
-            http.Error(w, "Failed to cancel specific child order", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to cancel specific child order")
             return
         }
 
@@ -268,7 +299,7 @@ func getTrades(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc {
         trades, err := omsClient.GetTrades(parentID)
         if err != nil {
             logger.Errorf("Failed to get trades for parent ID %s: %v", parentID, err)
-            http.Error(w, "Failed to retrieve trades", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to retrieve trades")
             return
         }
 
@@ -285,7 +316,7 @@ func deleteOrder(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc
         err := omsClient.DeleteOrder(parentID)
         if err != nil {
             logger.Errorf("Failed to delete order for parent ID %s: %v", parentID, err)
-            http.Error(w, "Failed to delete order", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to delete order")
             return
         }
 
@@ -296,15 +327,23 @@ func deleteOrder(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc
 // ORDER Handlers
 func getOrders(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
-        orders, err := omsClient.GetOrders()
+        query, err := oms.ParseOrderQuery(r.URL.Query())
+        if err != nil {
+            http.Error(w, err.Error(), http.StatusBadRequest)
+            return
+        }
+        page, err := omsClient.GetOrders(query)
         if err != nil {
             logger.Errorf("Failed to get orders: %v", err)
-            http.Error(w, "Failed to retrieve orders", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to retrieve orders")
             return
         }
 
         w.Header().Set("Content-Type", "application/json")
-        json.NewEncoder(w).Encode(orders)
+        if page.NextCursor != "" {
+            w.Header().Set("X-Next-Cursor", page.NextCursor)
+        }
+        w.Write(page.Orders)
     }
 }
 
@@ -316,17 +355,21 @@ func createOrder(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc
             http.Error(w, "Invalid request payload", http.StatusBadRequest)
             return
         }
+        // Retries carrying the same Idempotency-Key get the original order back
+        if key := r.Header.Get("Idempotency-Key"); key != "" && order.ClientOrderID == "" {
+            order.ClientOrderID = key
+        }
 
         createdOrder, err := omsClient.CreateOrder(order)
         if err != nil {
             logger.Errorf("Failed to create order: %v", err)
-            http.Error(w, "Failed to create order", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to create order")
             return
         }
 
         w.Header().Set("Content-Type", "application/json")
         w.WriteHeader(http.StatusCreated)
-        json.NewEncoder(w).Encode(createdOrder)
+        w.Write(createdOrder)
     }
 }
 
@@ -342,7 +385,7 @@ func executeOrder(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc
         err := omsClient.ExecuteOrder(orderID)
         if err != nil {
             logger.Errorf("Failed to execute order %s: %v", orderID, err)
-            http.Error(w, "Failed to execute order", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to execute order")
             return
         }
 
@@ -362,7 +405,7 @@ func cancelOrder(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc
         err := omsClient.CancelOrder(orderID)
         if err != nil {
             logger.Errorf("Failed to cancel order %s: %v", orderID, err)
-            http.Error(w, "Failed to cancel order", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to cancel order")
             return
         }
 
@@ -376,7 +419,7 @@ func getPositions(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc
         positions, err := omsClient.GetPositions()
         if err != nil {
             logger.Errorf("Failed to get positions: %v", err)
-            http.Error(w, "Failed to retrieve positions", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to retrieve positions")
             return
         }
 
@@ -390,7 +433,7 @@ func syncPosition(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc
         err := omsClient.SyncPositions()
         if err != nil {
             logger.Errorf("Failed to sync positions: %v", err)
-            http.Error(w, "Failed to sync positions", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to sync positions")
             return
         }
 
@@ -406,11 +449,10 @@ func convertPosition(logger *logger.Logger, omsClient *oms.Client) http.HandlerF
             return
         }
 
-        // Assuming ConvertPosition expects a string (like position.ID)
-        err := omsClient.ConvertPosition(position.ID) // Change this to the appropriate field
+        err := omsClient.ConvertPosition(position.ID, position.ToProduct, position.Quantity)
         if err != nil {
             logger.Errorf("Failed to convert position: %v", err)
-            http.Error(w, "Failed to convert position", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to convert position")
             return
         }
 
@@ -429,7 +471,7 @@ func createPositionOrder(logger *logger.Logger, omsClient *oms.Client) http.Hand
         err := omsClient.CreatePositionOrder(positionOrder) // Now it only takes one argument
         if err != nil {
             logger.Errorf("Failed to create position order: %v", err)
-            http.Error(w, "Failed to create position order", http.StatusInternalServerError)
+            writeOMSError(w, err, "Failed to create position order")
             return
         }
 
@@ -449,10 +491,69 @@ func deletePositionOrder(logger *logger.Logger, omsClient *oms.Client) http.Hand
 		err := omsClient.DeletePositionOrder(orderID)
 		if err != nil {
 			logger.Errorf("Failed to delete position order %s: %v", orderID, err)
-			http.Error(w, "Failed to delete position order", http.StatusInternalServerError)
+			writeOMSError(w, err, "Failed to delete position order")
 			return
 		}
 
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
+
+// ADMIN Handlers
+func getKillSwitches(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        switches, err := omsClient.GetKillSwitches()
+        if err != nil {
+            logger.Errorf("Failed to get kill switches: %v", err)
+            writeOMSError(w, err, "Failed to retrieve kill switches")
+            return
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        w.Write(switches)
+    }
+}
+
+func engageKillSwitch(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        var req oms.KillSwitchRequest
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            logger.Errorf("Failed to decode kill switch request: %v", err)
+            http.Error(w, "Invalid request payload", http.StatusBadRequest)
+            return
+        }
+        req.Actor = auth.CallerFrom(r).UserID
+
+        ks, err := omsClient.EngageKillSwitch(req)
+        if err != nil {
+            logger.Errorf("Failed to engage kill switch for account %q: %v", req.AccountID, err)
+            writeOMSError(w, err, "Failed to engage kill switch")
+            return
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        w.Write(ks)
+    }
+}
+
+func releaseKillSwitch(logger *logger.Logger, omsClient *oms.Client) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        var req oms.KillSwitchRequest
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            logger.Errorf("Failed to decode kill switch request: %v", err)
+            http.Error(w, "Invalid request payload", http.StatusBadRequest)
+            return
+        }
+        req.Actor = auth.CallerFrom(r).UserID
+
+        ks, err := omsClient.ReleaseKillSwitch(req)
+        if err != nil {
+            logger.Errorf("Failed to release kill switch for account %q: %v", req.AccountID, err)
+            writeOMSError(w, err, "Failed to release kill switch")
+            return
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        w.Write(ks)
+    }
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// Caller headers name the account and user a request is made for and the
+// role they act in. The gateway in front of the OMS authenticates callers and
+// sets them, and signs them with the secret it shares with the OMS so clients
+// cannot name a caller of their own.
+const (
+	HeaderAccountID       = "X-Account-ID"
+	HeaderUserID          = "X-User-ID"
+	HeaderRole            = "X-Role"
+	HeaderCallerTimestamp = "X-Caller-Timestamp"
+	HeaderCallerSignature = "X-Caller-Signature"
+)
+
+// callerSignatureMaxAge is how far a caller signature's timestamp may be from
+// the OMS clock.
+const callerSignatureMaxAge = 5 * time.Minute
+
+// Option configures the HTTP and NATS handlers.
+type Option func(*Handlers)
+
+// WithCallerSecret sets the secret the caller headers are signed with. Without
+// one, no caller is trusted and every request is answered 401.
+func WithCallerSecret(secret []byte) Option {
+	return func(h *Handlers) {
+		h.callerSecret = secret
+	}
+}
+
+// SignCaller returns the X-Caller-Signature of the caller headers accountID,
+// userID and role, as sent, at the unix time timestamp: the hex HMAC-SHA256
+// under secret of the timestamp and the three values, one per line.
+func SignCaller(secret []byte, accountID, userID, role string, timestamp int64) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "\n" + accountID + "\n" + userID + "\n" + role))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyCaller checks the caller headers of r against their signature.
+func (h *Handlers) verifyCaller(r *http.Request) bool {
+	if len(h.callerSecret) == 0 {
+		return false
+	}
+	timestamp, err := strconv.ParseInt(r.Header.Get(HeaderCallerTimestamp), 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age > callerSignatureMaxAge || age < -callerSignatureMaxAge {
+		return false
+	}
+	want := SignCaller(h.callerSecret, r.Header.Get(HeaderAccountID), r.Header.Get(HeaderUserID), r.Header.Get(HeaderRole), timestamp)
+	return hmac.Equal([]byte(r.Header.Get(HeaderCallerSignature)), []byte(want))
+}
+
+// callerFrom reads the caller of r from its headers, which must carry a fresh
+// signature. A caller without a role is a trader, and a trader must name its
+// account. It answers 401 and returns false when r has no valid caller.
+func (h *Handlers) callerFrom(w http.ResponseWriter, r *http.Request) (models.Caller, bool) {
+	caller := models.Caller{
+		AccountID: r.Header.Get(HeaderAccountID),
+		UserID:    r.Header.Get(HeaderUserID),
+		Role:      models.Role(r.Header.Get(HeaderRole)),
+	}
+	if !h.verifyCaller(r) {
+		writeErrorCode(w, CodeUnauthorized, "caller headers are not signed")
+		return caller, false
+	}
+	if caller.Role == "" {
+		caller.Role = models.RoleTrader
+	}
+	switch {
+	case caller.Role != models.RoleTrader && caller.Role != models.RoleOperator:
+		writeErrorCode(w, CodeUnauthorized, "unknown role "+string(caller.Role))
+		return caller, false
+	case caller.Role == models.RoleTrader && caller.AccountID == "":
+		writeErrorCode(w, CodeUnauthorized, HeaderAccountID+" is required")
+		return caller, false
+	}
+	return caller, true
+}
+
+// authorize reads the caller of r and checks that it may act on every order
+// or scalper order in ids. It answers 401 or 403 and returns false when not.
+func (h *Handlers) authorize(w http.ResponseWriter, r *http.Request, ids ...string) (models.Caller, bool) {
+	caller, ok := h.callerFrom(w, r)
+	if !ok {
+		return caller, false
+	}
+	for _, id := range ids {
+		if err := h.omsService.Authorize(caller, id); err != nil {
+			writeError(w, err)
+			return caller, false
+		}
+	}
+	return caller, true
+}
+
+// authorizeAccount reads the caller of r and checks that it may act on
+// accountID, where empty is the whole OMS. It answers 401 or 403 and returns
+// false when not.
+func (h *Handlers) authorizeAccount(w http.ResponseWriter, r *http.Request, accountID string) (models.Caller, bool) {
+	caller, ok := h.callerFrom(w, r)
+	if !ok {
+		return caller, false
+	}
+	if err := h.omsService.AuthorizeAccount(caller, accountID); err != nil {
+		writeError(w, err)
+		return caller, false
+	}
+	return caller, true
+}
+
+// stampOwner reads the caller of r and stamps it as the owner of an order it
+// places: accountID defaults to the caller's account and userID is always the
+// caller's user. It answers 401 or 403 and returns false when the caller may
+// not place orders for accountID.
+func (h *Handlers) stampOwner(w http.ResponseWriter, r *http.Request, accountID, userID *string) bool {
+	caller, ok := h.callerFrom(w, r)
+	if !ok {
+		return false
+	}
+	if *accountID == "" {
+		*accountID = caller.AccountID
+	}
+	if err := h.omsService.AuthorizeAccount(caller, *accountID); err != nil {
+		writeError(w, err)
+		return false
+	}
+	*userID = caller.UserID
+	return true
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+// CodeUnauthorized is the error code of requests without a valid caller.
+const CodeUnauthorized service.ErrorCode = "unauthorized"
+
+// ErrorResponse is the body of every error answer of the API. Code is stable
+// for clients to branch on; Message is meant for people.
+type ErrorResponse struct {
+	Code    service.ErrorCode  `json:"code"`
+	Message string             `json:"message"`
+	Risk    *service.RiskError `json:"risk,omitempty"` // The check that rejected the order, for risk_rejected
+}
+
+// errorStatus is the HTTP status each error code is answered with.
+var errorStatus = map[service.ErrorCode]int{
+	service.CodeValidationFailed:  http.StatusBadRequest,
+	CodeUnauthorized:              http.StatusUnauthorized,
+	service.CodeAccessDenied:      http.StatusForbidden,
+	service.CodeNotFound:          http.StatusNotFound,
+	service.CodeInvalidTransition: http.StatusConflict,
+	service.CodeConflict:          http.StatusConflict,
+	service.CodeRiskRejected:      http.StatusUnprocessableEntity,
+	service.CodeInternal:          http.StatusInternalServerError,
+}
+
+// writeError answers with err as an ErrorResponse, under the status of its
+// kind.
+func writeError(w http.ResponseWriter, err error) {
+	resp := ErrorResponse{Code: service.Code(err), Message: err.Error()}
+	errors.As(err, &resp.Risk)
+	writeErrorResponse(w, resp)
+}
+
+// writeErrorCode answers with an ErrorResponse of code and message.
+func writeErrorCode(w http.ResponseWriter, code service.ErrorCode, message string) {
+	writeErrorResponse(w, ErrorResponse{Code: code, Message: message})
+}
+
+func writeErrorResponse(w http.ResponseWriter, resp ErrorResponse) {
+	status, ok := errorStatus[resp.Code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
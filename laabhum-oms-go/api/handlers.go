@@ -2,7 +2,13 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/Mukilan-T/laabhum-oms-go/models"
 	"github.com/Mukilan-T/laabhum-oms-go/repository"
@@ -12,20 +18,25 @@ import (
 
 // Handlers struct to hold OMSService
 type Handlers struct {
-	omsService *service.OMSService
+	omsService   *service.OMSService
+	callerSecret []byte
 }
 
 // NewHandlers initializes the Handlers
-func NewHandlers(omsService *service.OMSService) *Handlers {
-	return &Handlers{
+func NewHandlers(omsService *service.OMSService, opts ...Option) *Handlers {
+	h := &Handlers{
 		omsService: omsService,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // Helper function to bind JSON and handle errors
 func bindJSON(w http.ResponseWriter, r *http.Request, obj interface{}) error {
 	if err := json.NewDecoder(r.Body).Decode(obj); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeErrorCode(w, service.CodeValidationFailed, err.Error())
 		return err
 	}
 	return nil
@@ -37,10 +48,21 @@ func (h *Handlers) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	if err := bindJSON(w, r, &order); err != nil {
 		return
 	}
+	// An Idempotency-Key header stands in for client_order_id.
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		if order.ClientOrderID != "" && order.ClientOrderID != key {
+			writeErrorCode(w, service.CodeValidationFailed, "Idempotency-Key and client_order_id differ")
+			return
+		}
+		order.ClientOrderID = key
+	}
+	if !h.stampOwner(w, r, &order.AccountID, &order.UserID) {
+		return
+	}
 
 	createdOrder, err := h.omsService.CreateOrder(order)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -54,10 +76,44 @@ func (h *Handlers) CreateScalperOrder(w http.ResponseWriter, r *http.Request) {
 	if err := bindJSON(w, r, &order); err != nil {
 		return
 	}
+	if order.AccountID == "" {
+		order.AccountID = order.ParentOrder.AccountID
+	}
+	if !h.stampOwner(w, r, &order.AccountID, &order.UserID) {
+		return
+	}
+
+	createdOrder, err := h.omsService.CreateScalperOrder(order)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdOrder)
+}
+
+// CreateBracketOrder handles the creation of a bracket order: a scalper order
+// with a single entry and an attached target and stop-loss
+func (h *Handlers) CreateBracketOrder(w http.ResponseWriter, r *http.Request) {
+	var order models.ScalperOrder
+	if err := bindJSON(w, r, &order); err != nil {
+		return
+	}
+	if order.Bracket == nil {
+		writeErrorCode(w, service.CodeValidationFailed, "bracket is required")
+		return
+	}
+	if order.AccountID == "" {
+		order.AccountID = order.ParentOrder.AccountID
+	}
+	if !h.stampOwner(w, r, &order.AccountID, &order.UserID) {
+		return
+	}
 
 	createdOrder, err := h.omsService.CreateScalperOrder(order)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -65,15 +121,43 @@ func (h *Handlers) CreateScalperOrder(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(createdOrder)
 }
 
+// ModifyScalperOrder handles resizing a bracket order
+func (h *Handlers) ModifyScalperOrder(w http.ResponseWriter, r *http.Request) {
+	parentID := mux.Vars(r)["parentID"]
+	if _, ok := h.authorize(w, r, parentID); !ok {
+		return
+	}
+	var req struct {
+		Quantity int `json:"quantity"`
+		Version  int `json:"version"`
+	}
+	if err := bindJSON(w, r, &req); err != nil {
+		return
+	}
+
+	order, err := h.omsService.ModifyScalperQuantity(parentID, req.Quantity, req.Version)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
 // ExecuteChildOrder handles executing a child order
 func (h *Handlers) ExecuteChildOrder(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	parentID := vars["parentID"]
 	childID := vars["childID"]
+	caller, ok := h.authorize(w, r, parentID)
+	if !ok {
+		return
+	}
 
-	err := h.omsService.ExecuteChildOrder(parentID, childID)
+	err := h.omsService.ExecuteChildOrder(parentID, childID, caller.UserID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -81,13 +165,318 @@ func (h *Handlers) ExecuteChildOrder(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"message": "Child order executed successfully"})
 }
 
+// GetScalperOrder handles fetching a scalper order with its child orders
+func (h *Handlers) GetScalperOrder(w http.ResponseWriter, r *http.Request) {
+	parentID := mux.Vars(r)["parentID"]
+	if _, ok := h.authorize(w, r, parentID); !ok {
+		return
+	}
+
+	order, err := h.omsService.GetScalperOrder(parentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// ExecuteAllChildOrders handles executing every working child of a scalper order
+func (h *Handlers) ExecuteAllChildOrders(w http.ResponseWriter, r *http.Request) {
+	parentID := mux.Vars(r)["parentID"]
+	if _, ok := h.authorize(w, r, parentID); !ok {
+		return
+	}
+
+	err := h.omsService.ExecuteAllChildOrders(parentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Child orders executed successfully"})
+}
+
+// CancelScalperOrder handles canceling every working child of a scalper order
+func (h *Handlers) CancelScalperOrder(w http.ResponseWriter, r *http.Request) {
+	parentID := mux.Vars(r)["parentID"]
+	if _, ok := h.authorize(w, r, parentID); !ok {
+		return
+	}
+
+	err := h.omsService.CancelScalperOrder(parentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Child orders canceled successfully"})
+}
+
+// ctcRequest is the optional body of the CTC routes. LTP overrides the last
+// traded price known to the OMS.
+type ctcRequest struct {
+	LTP float64 `json:"ltp"`
+}
+
+// bindCTCRequest reads an optional ctcRequest; an empty body is allowed.
+func bindCTCRequest(w http.ResponseWriter, r *http.Request) (ctcRequest, error) {
+	var req ctcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeErrorCode(w, service.CodeValidationFailed, err.Error())
+		return req, err
+	}
+	return req, nil
+}
+
+// CTCOrder handles moving the stop-loss of every profitable child to cost
+func (h *Handlers) CTCOrder(w http.ResponseWriter, r *http.Request) {
+	parentID := mux.Vars(r)["parentID"]
+	if _, ok := h.authorize(w, r, parentID); !ok {
+		return
+	}
+	req, err := bindCTCRequest(w, r)
+	if err != nil {
+		return
+	}
+
+	order, err := h.omsService.CTCOrder(parentID, req.LTP)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// CTCChildOrder handles moving the stop-loss of one child to cost
+func (h *Handlers) CTCChildOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	parentID := vars["parentID"]
+	childID := vars["childID"]
+	if _, ok := h.authorize(w, r, parentID); !ok {
+		return
+	}
+	req, err := bindCTCRequest(w, r)
+	if err != nil {
+		return
+	}
+
+	order, err := h.omsService.CTCChildOrder(parentID, childID, req.LTP)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// ExitAllTrades handles flattening every open position of the caller's
+// account. Operators flatten the account named by ?account_id=, or the whole
+// OMS without one.
+func (h *Handlers) ExitAllTrades(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.callerFrom(w, r)
+	if !ok {
+		return
+	}
+	exits, err := h.omsService.ExitAllTrades(caller.Scope(r.URL.Query().Get("account_id")))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(exits)
+}
+
+// ExitScalperTrades handles flattening every child of a scalper order
+func (h *Handlers) ExitScalperTrades(w http.ResponseWriter, r *http.Request) {
+	parentID := mux.Vars(r)["parentID"]
+	if _, ok := h.authorize(w, r, parentID); !ok {
+		return
+	}
+
+	exits, err := h.omsService.ExitScalperTrades(parentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(exits)
+}
+
+// ExitChildTrade handles flattening one child of a scalper order
+func (h *Handlers) ExitChildTrade(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	parentID := vars["parentID"]
+	childID := vars["childID"]
+	if _, ok := h.authorize(w, r, parentID); !ok {
+		return
+	}
+
+	exits, err := h.omsService.ExitChildTrade(parentID, childID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(exits)
+}
+
+// EngageKillSwitch handles blocking new orders for an account, or the whole
+// OMS when no account_id is sent, canceling its resting orders and optionally
+// flattening its positions. Only operators may engage a switch.
+func (h *Handlers) EngageKillSwitch(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.bindKillSwitchRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ks, err := h.omsService.EngageKillSwitch(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ks)
+}
+
+// ReleaseKillSwitch handles letting orders through again
+func (h *Handlers) ReleaseKillSwitch(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.bindKillSwitchRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ks, err := h.omsService.ReleaseKillSwitch(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ks)
+}
+
+// bindKillSwitchRequest reads a kill switch request and checks the caller is
+// an operator; traders may neither engage nor release a switch, even their
+// own account's. The actor is always the caller's user, whatever the body
+// says.
+func (h *Handlers) bindKillSwitchRequest(w http.ResponseWriter, r *http.Request) (service.KillSwitchRequest, bool) {
+	var req service.KillSwitchRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		return req, false
+	}
+	caller, ok := h.authorizeAccount(w, r, "")
+	if !ok {
+		return req, false
+	}
+	req.Actor = caller.UserID
+	return req, true
+}
+
+// GetKillSwitches handles listing kill switches with their audit trails.
+// Traders see their own account's switch and the OMS-wide one.
+func (h *Handlers) GetKillSwitches(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.callerFrom(w, r)
+	if !ok {
+		return
+	}
+	all, err := h.omsService.GetKillSwitches()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	switches := []models.KillSwitch{}
+	for _, ks := range all {
+		if ks.AccountID == "" || caller.CanAccess(ks.AccountID) {
+			switches = append(switches, ks)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(switches)
+}
+
+// GetPositions handles listing the positions of the caller's account,
+// optionally filtered by ?symbol= and ?product=. Operators see every account,
+// or the one named by ?account_id=.
+func (h *Handlers) GetPositions(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.callerFrom(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	positions := h.omsService.GetPositions(caller.Scope(query.Get("account_id")), query.Get("symbol"), models.ProductType(query.Get("product")))
+	if positions == nil {
+		positions = []models.Position{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(positions)
+}
+
+// SyncPositions handles rebuilding the position book from recorded trades.
+// It rebuilds every account, so only operators may.
+func (h *Handlers) SyncPositions(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorizeAccount(w, r, ""); !ok {
+		return
+	}
+	if err := h.omsService.SyncPositions(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ConvertPosition handles moving a position to another product type. The
+// position is addressed by its ID, "<symbol>:<product>", within the caller's
+// account; the body carries to_product and quantity, and operators name the
+// account with account_id.
+func (h *Handlers) ConvertPosition(w http.ResponseWriter, r *http.Request) {
+	var conv models.PositionConversion
+	if err := bindJSON(w, r, &conv); err != nil {
+		return
+	}
+	if !h.stampOwner(w, r, &conv.AccountID, &conv.UserID) {
+		return
+	}
+	positionID := mux.Vars(r)["positionID"]
+	separator := strings.LastIndex(positionID, ":")
+	if separator < 0 {
+		writeErrorCode(w, service.CodeValidationFailed, "position id must be <symbol>:<product>")
+		return
+	}
+	conv.Symbol = positionID[:separator]
+	conv.FromProduct = models.ProductType(positionID[separator+1:])
+
+	if _, err := h.omsService.ConvertPosition(conv); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // GetTrades handles fetching trades for a parent order
 func (h *Handlers) GetTrades(w http.ResponseWriter, r *http.Request) {
 	parentID := mux.Vars(r)["parentId"]
+	if _, ok := h.authorize(w, r, parentID); !ok {
+		return
+	}
 
 	trades, err := h.omsService.GetTrades(parentID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -95,36 +484,164 @@ func (h *Handlers) GetTrades(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(trades)
 }
 
-// GetOrders handles fetching all orders
+// RecordFill handles booking an execution against an order. Replaying an
+// execution that was already booked returns the original trade with 200.
+// Fills move positions and P&L, so only operators may book them; the broker's
+// execution reports arrive over Kafka.
+func (h *Handlers) RecordFill(w http.ResponseWriter, r *http.Request) {
+	var fill models.Fill
+	if err := bindJSON(w, r, &fill); err != nil {
+		return
+	}
+	fill.OrderID = mux.Vars(r)["id"]
+	caller, ok := h.authorizeAccount(w, r, "")
+	if !ok {
+		return
+	}
+	fill.Actor = caller.UserID
+
+	trade, recorded, err := h.omsService.RecordFill(fill)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if recorded {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(trade)
+}
+
+// GetOrders handles listing the orders of the caller's account a page at a
+// time, filtered by ?status= (repeated or comma separated), ?symbol=, ?side=,
+// ?parent_id= and ?created_from= and ?created_to= in Unix seconds. ?sort=,
+// ?limit= and ?cursor= page through them; the cursor of the next page is
+// returned in the X-Next-Cursor header. Operators see every account, or the
+// one named by ?account_id=.
 func (h *Handlers) GetOrders(w http.ResponseWriter, r *http.Request) {
-	orders, err := h.omsService.GetOrders()
+	caller, ok := h.callerFrom(w, r)
+	if !ok {
+		return
+	}
+	query, err := orderQuery(r.URL.Query(), caller)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeErrorCode(w, service.CodeValidationFailed, err.Error())
+		return
+	}
+	page, err := h.omsService.QueryOrders(query)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(orders)
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+	json.NewEncoder(w).Encode(page.Orders)
 }
 
-// ModifyOrder handles modifying an order
+// orderQuery reads the order query in the parameters of GET /orders, scoped
+// to what caller may see.
+func orderQuery(params url.Values, caller models.Caller) (service.OrderQuery, error) {
+	query := service.OrderQuery{
+		Filter: service.OrderFilter{
+			AccountID: caller.Scope(params.Get("account_id")),
+			ParentID:  params.Get("parent_id"),
+			Symbol:    params.Get("symbol"),
+			Side:      params.Get("side"),
+		},
+		Sort:   params.Get("sort"),
+		Cursor: params.Get("cursor"),
+	}
+	for _, statuses := range params["status"] {
+		for _, status := range strings.Split(statuses, ",") {
+			query.Filter.Statuses = append(query.Filter.Statuses, models.OrderStatus(strings.TrimSpace(status)))
+		}
+	}
+	for name, value := range map[string]*int64{
+		"created_from": &query.Filter.CreatedFrom,
+		"created_to":   &query.Filter.CreatedTo,
+	} {
+		if params.Get(name) == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(params.Get(name), 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("%s must be a Unix time in seconds", name)
+		}
+		*value = parsed
+	}
+	if limit := params.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 {
+			return query, errors.New("limit must be a positive number")
+		}
+		query.Limit = parsed
+	}
+	return query, nil
+}
+
+// GetOrder handles fetching one order
+func (h *Handlers) GetOrder(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if _, ok := h.authorize(w, r, id); !ok {
+		return
+	}
+
+	order, err := h.omsService.GetOrder(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// GetOrderHistory handles listing the status transitions, amendments and
+// fills of one order with who made them and when
+func (h *Handlers) GetOrderHistory(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if _, ok := h.authorize(w, r, id); !ok {
+		return
+	}
+
+	history, err := h.omsService.OrderHistory(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// ModifyOrder handles amending an order. The amendment is recorded as made
+// by the caller, whatever actor the body names.
 func (h *Handlers) ModifyOrder(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	parentID := vars["parentId"]
 	childID := vars["childId"]
-	var newData map[string]interface{}
-	if err := bindJSON(w, r, &newData); err != nil {
+	caller, ok := h.authorize(w, r, parentID, childID)
+	if !ok {
+		return
+	}
+	var req models.AmendRequest
+	if err := bindJSON(w, r, &req); err != nil {
 		return
 	}
+	req.Actor = caller.UserID
 
-	err := h.omsService.ModifyOrder(parentID, childID, newData)
+	order, err := h.omsService.AmendOrder(parentID, childID, req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Order modified successfully"})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
 }
 
 // CancelOrder handles canceling an order
@@ -132,10 +649,14 @@ func (h *Handlers) CancelOrder(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	parentID := vars["parentId"]
 	orderID := vars["orderId"]
+	caller, ok := h.authorize(w, r, parentID, orderID)
+	if !ok {
+		return
+	}
 
-	err := h.omsService.CancelOrder(parentID, orderID)
+	err := h.omsService.CancelOrder(parentID, orderID, caller.UserID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -144,19 +665,44 @@ func (h *Handlers) CancelOrder(w http.ResponseWriter, r *http.Request) {
 }
 
 // SetupRoutes sets up the routes for the API
-func SetupRoutes(repo repository.OrderRepository, omsService *service.OMSService) *mux.Router {
+func SetupRoutes(repo repository.OrderRepository, omsService *service.OMSService, opts ...Option) *mux.Router {
 	router := mux.NewRouter()
-	h := NewHandlers(omsService)
+	h := NewHandlers(omsService, opts...)
 
 	// Order routes
 	router.HandleFunc("/orders", h.CreateOrder).Methods(http.MethodPost)
 	router.HandleFunc("/orders", h.GetOrders).Methods(http.MethodGet)
+	router.HandleFunc("/orders/{id}", h.GetOrder).Methods(http.MethodGet)
+	router.HandleFunc("/orders/{id}/history", h.GetOrderHistory).Methods(http.MethodGet)
+	router.HandleFunc("/orders/{id}/fills", h.RecordFill).Methods(http.MethodPost)
 
 	// Scalper order routes
 	router.HandleFunc("/oms/scalper/order", h.CreateScalperOrder).Methods(http.MethodPost)
+	router.HandleFunc("/oms/scalper/order/{parentID}", h.GetScalperOrder).Methods(http.MethodGet)
+	router.HandleFunc("/oms/scalper/order/{parentID}/modify", h.ModifyScalperOrder).Methods(http.MethodPatch)
+	router.HandleFunc("/oms/bracket/order", h.CreateBracketOrder).Methods(http.MethodPost)
+	router.HandleFunc("/oms/scalper/order/{parentID}/execute", h.ExecuteAllChildOrders).Methods(http.MethodPost)
+	router.HandleFunc("/oms/scalper/order/{parentID}/cancel", h.CancelScalperOrder).Methods(http.MethodPost)
 	router.HandleFunc("/oms/scalper/order/{parentID}/{childID}/execute", h.ExecuteChildOrder).Methods(http.MethodPost)
+	router.HandleFunc("/oms/scalper/order/{parentID}/ctc", h.CTCOrder).Methods(http.MethodPost)
+	router.HandleFunc("/oms/scalper/order/{parentID}/{childID}/ctc", h.CTCChildOrder).Methods(http.MethodPost)
 	router.HandleFunc("/oms/scalper/trades/{parentId}", h.GetTrades).Methods(http.MethodGet)
 
+	// Position routes
+	router.HandleFunc("/oms/positions", h.GetPositions).Methods(http.MethodGet)
+	router.HandleFunc("/oms/positions/sync", h.SyncPositions).Methods(http.MethodPost)
+	router.HandleFunc("/oms/positions/{positionID}/convert", h.ConvertPosition).Methods(http.MethodPost)
+
+	// Exit routes
+	router.HandleFunc("/oms/scalper/exit/trade", h.ExitAllTrades).Methods(http.MethodPost)
+	router.HandleFunc("/oms/scalper/trade/{parentID}/exit", h.ExitScalperTrades).Methods(http.MethodPost)
+	router.HandleFunc("/oms/scalper/trade/{parentID}/{childID}/exit", h.ExitChildTrade).Methods(http.MethodPost)
+
+	// Admin routes
+	router.HandleFunc("/oms/admin/kill-switch", h.GetKillSwitches).Methods(http.MethodGet)
+	router.HandleFunc("/oms/admin/kill-switch/engage", h.EngageKillSwitch).Methods(http.MethodPost)
+	router.HandleFunc("/oms/admin/kill-switch/release", h.ReleaseKillSwitch).Methods(http.MethodPost)
+
 	// Order modification routes
 	router.HandleFunc("/oms/scalper/order/{parentId}/{childId}/modify", h.ModifyOrder).Methods(http.MethodPatch)
 	router.HandleFunc("/oms/scalper/order/{parentId}/{orderId}/cancel", h.CancelOrder).Methods(http.MethodPost)
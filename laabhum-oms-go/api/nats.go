@@ -0,0 +1,118 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/pkg/nats"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+	"github.com/gorilla/mux"
+)
+
+// orderCommand addresses an order in a modify or cancel command. OrderID alone
+// names a standalone order; a scalper child also needs ParentID. A modify
+// command carries the amendment alongside.
+type orderCommand struct {
+	ParentID string `json:"parent_id"`
+	OrderID  string `json:"order_id"`
+	models.AmendRequest
+}
+
+// ServeNATSCommands answers create, modify and cancel commands sent over NATS
+// by running them through the HTTP handlers, so a command gets exactly the
+// reply body and status the HTTP API would give. Commands name their caller
+// with the same signed headers as HTTP requests.
+func ServeNATSCommands(client *nats.NatsClient, omsService *service.OMSService, opts ...Option) error {
+	h := NewHandlers(omsService, opts...)
+	commands := map[string]nats.CommandHandler{
+		nats.SubjectCreateOrder: func(request []byte, headers http.Header) (int, []byte) {
+			return serveCommand(h.CreateOrder, request, headers, nil)
+		},
+		nats.SubjectModifyOrder: func(request []byte, headers http.Header) (int, []byte) {
+			return serveOrderCommand(h.ModifyOrder, request, headers, true)
+		},
+		nats.SubjectCancelOrder: func(request []byte, headers http.Header) (int, []byte) {
+			return serveOrderCommand(h.CancelOrder, request, headers, false)
+		},
+	}
+	for subject, handler := range commands {
+		if err := client.HandleCommand(subject, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serveOrderCommand unpacks an orderCommand into the route variables of the
+// scalper order routes, which also serve standalone orders, and the amendment
+// into the request body.
+func serveOrderCommand(handler http.HandlerFunc, request []byte, headers http.Header, withAmendment bool) (int, []byte) {
+	var cmd orderCommand
+	if err := json.Unmarshal(request, &cmd); err != nil {
+		return errorReply(service.CodeValidationFailed, err.Error())
+	}
+	var body []byte
+	if withAmendment {
+		body, _ = json.Marshal(cmd.AmendRequest)
+	}
+	return serveCommand(handler, body, headers, map[string]string{
+		"parentId": cmd.ParentID,
+		"childId":  cmd.OrderID,
+		"orderId":  cmd.OrderID,
+	})
+}
+
+// serveCommand runs handler on an in-process request with body, headers and
+// route variables vars, and returns the response status and body.
+func serveCommand(handler http.HandlerFunc, body []byte, headers http.Header, vars map[string]string) (int, []byte) {
+	r, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	if err != nil {
+		return errorReply(service.CodeInternal, err.Error())
+	}
+	if headers != nil {
+		r.Header = headers.Clone()
+	}
+	r.Header.Set("Content-Type", "application/json")
+	if vars != nil {
+		r = mux.SetURLVars(r, vars)
+	}
+	w := &replyWriter{header: make(http.Header)}
+	handler(w, r)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.status, w.body.Bytes()
+}
+
+// errorReply returns the status and body of the error answer of code and
+// message.
+func errorReply(code service.ErrorCode, message string) (int, []byte) {
+	w := &replyWriter{header: make(http.Header)}
+	writeErrorCode(w, code, message)
+	return w.status, w.body.Bytes()
+}
+
+// replyWriter is an http.ResponseWriter that buffers the response for a
+// command reply.
+type replyWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *replyWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *replyWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *replyWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
@@ -3,26 +3,138 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
+	_ "time/tzdata" // the session timezone must load in minimal containers
 
 	"github.com/Mukilan-T/laabhum-oms-go/api"
+	"github.com/Mukilan-T/laabhum-oms-go/config"
+	"github.com/Mukilan-T/laabhum-oms-go/journal"
 	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/pkg/adapter"
+	"github.com/Mukilan-T/laabhum-oms-go/pkg/kafka"
+	"github.com/Mukilan-T/laabhum-oms-go/pkg/nats"
 	"github.com/Mukilan-T/laabhum-oms-go/repository"
 	"github.com/Mukilan-T/laabhum-oms-go/service"
 )
 
 func main() {
+	configPath := os.Getenv("OMS_CONFIG")
+	if configPath == "" {
+		configPath = "config.yaml"
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cfg.Auth.CallerSecret == "" {
+		log.Fatalf("auth.caller_secret (or OMS_CALLER_SECRET) is required to trust the gateway's caller headers")
+	}
+	callerSecret := api.WithCallerSecret([]byte(cfg.Auth.CallerSecret))
+
 	// Initialize repository and service
-	repo := repository.NewInMemoryOrderRepository()
-	omsService := service.NewOMSService(repo)
+	repo, closeRepo, err := newRepository(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize %s repository: %v", cfg.Storage.Driver, err)
+	}
+	defer closeRepo()
+
+	session, err := tradingSession(cfg)
+	if err != nil {
+		log.Fatalf("Invalid orders configuration: %v", err)
+	}
+	opts := []service.Option{
+		service.WithTradingSession(session),
+		service.WithIdempotencyWindow(time.Duration(cfg.Orders.IdempotencyHours) * time.Hour),
+		service.WithSlicingRule(service.SlicingRule{
+			Legs:             cfg.Scalper.Legs,
+			MaxChildQuantity: cfg.Scalper.MaxChildQuantity,
+		}),
+		service.WithCTCCosts(service.CTCCosts{
+			PerUnit: cfg.CTC.CostPerUnit,
+			Bps:     cfg.CTC.CostBps,
+		}),
+		service.WithRiskChecks(service.RiskChecks(service.RiskLimits{
+			RestrictedSymbols:  cfg.Risk.RestrictedSymbols,
+			MaxQuantity:        cfg.Risk.MaxOrderQuantity,
+			MaxNotional:        cfg.Risk.MaxOrderNotional,
+			PriceBandPercent:   cfg.Risk.PriceBandPercent,
+			MaxPosition:        cfg.Risk.MaxPosition,
+			MaxDailyLoss:       cfg.Risk.MaxDailyLoss,
+			MaxOrdersPerSecond: cfg.Risk.MaxOrdersPerSecond,
+		})...),
+	}
+	var adapterClient *adapter.Client
+	if cfg.Adapter.URL != "" {
+		adapterClient = adapter.NewClient(cfg.Adapter.URL)
+		opts = append(opts, service.WithBrokerAdapter(adapterClient))
+	}
+	if len(cfg.Kafka.Brokers) > 0 {
+		publisher, err := kafka.NewPublisher(cfg.Kafka.Brokers, cfg.Kafka.Topic)
+		if err != nil {
+			log.Fatalf("Failed to connect to Kafka: %v", err)
+		}
+		defer publisher.Close()
+		opts = append(opts, service.WithEventPublisher(publisher))
+	}
+	var natsClient *nats.NatsClient
+	if cfg.NATS.URL != "" {
+		natsClient, err = nats.NewNatsClient(cfg.NATS.URL)
+		if err != nil {
+			log.Fatalf("Failed to connect to NATS: %v", err)
+		}
+		defer natsClient.Close()
+		opts = append(opts, service.WithEventPublisher(natsClient))
+	}
+	if cfg.Journal.Dir != "" {
+		j, err := openJournal(cfg, repo)
+		if err != nil {
+			log.Fatalf("Failed to open journal: %v", err)
+		}
+		defer j.Close()
+		opts = append(opts, service.WithJournal(j))
+	}
+	omsService := service.NewOMSService(repo, opts...)
+	if err := omsService.SyncPositions(); err != nil {
+		log.Fatalf("Failed to build positions: %v", err)
+	}
+
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	if cfg.Orders.ExpiryCheckSeconds > 0 {
+		go omsService.RunExpiry(background, time.Duration(cfg.Orders.ExpiryCheckSeconds)*time.Second)
+	}
+	if len(cfg.Kafka.Brokers) > 0 || natsClient != nil {
+		go omsService.RunOutboxRelay(background, cfg.Outbox.BatchSize, time.Duration(cfg.Outbox.RetrySeconds)*time.Second)
+	}
+	if cfg.Kafka.ExecutionTopic != "" {
+		consumer, err := kafka.NewExecutionConsumer(cfg.Kafka.Brokers, cfg.Kafka.GroupID, cfg.Kafka.ExecutionTopic)
+		if err != nil {
+			log.Fatalf("Failed to consume execution reports: %v", err)
+		}
+		defer consumer.Close()
+		go consumeExecutionReports(background, consumer, omsService)
+	}
+	if natsClient != nil {
+		if err := api.ServeNATSCommands(natsClient, omsService, callerSecret); err != nil {
+			log.Fatalf("Failed to serve NATS commands: %v", err)
+		}
+	}
+	if len(cfg.MarketData.Symbols) > 0 {
+		if adapterClient == nil {
+			log.Fatalf("market_data.symbols needs adapter.url")
+		}
+		streamMarketData(background, adapterClient, omsService, cfg)
+	}
 
 	// Set up routes
-	router := api.SetupRoutes(repo, omsService)
+	router := api.SetupRoutes(repo, omsService, callerSecret)
 
 	// Add global middleware
 	router.Use(loggingMiddleware)
@@ -31,7 +143,7 @@ func main() {
 
 	// Configure server
 	srv := &http.Server{
-		Addr:         ":8081",
+		Addr:         cfg.Server.Address,
 		Handler:      router,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
@@ -41,7 +153,7 @@ func main() {
 	// Graceful shutdown
 	go func() {
 		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt)
+		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
 		<-sigint
 
 		// We received an interrupt signal, shut down.
@@ -51,12 +163,135 @@ func main() {
 	}()
 
 	// Start the server
-	logInfo("Server started at :8081")
+	logInfo("Server started", "address", cfg.Server.Address, "storage", cfg.Storage.Driver)
 	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
 		log.Fatalf("HTTP server ListenAndServe: %v", err)
 	}
 }
 
+// newRepository builds the order repository selected by the storage config.
+// The returned function releases any resources held by the repository.
+func newRepository(cfg *config.Config) (repository.OrderRepository, func(), error) {
+	switch cfg.Storage.Driver {
+	case "", "memory":
+		repo := repository.NewInMemoryOrderRepository()
+		path := cfg.Storage.SnapshotPath
+		if path == "" || cfg.Journal.Dir != "" {
+			return repo, func() {}, nil
+		}
+		if err := repo.RestoreFromFile(path); err != nil {
+			return nil, nil, err
+		}
+		logInfo("Restored in-memory store", "path", path)
+		return repo, func() {
+			if err := repo.SnapshotToFile(path); err != nil {
+				logError(err, "Saving in-memory snapshot")
+				return
+			}
+			logInfo("Saved in-memory store", "path", path)
+		}, nil
+	case "postgres", "sqlite":
+		if cfg.Storage.DSN == "" {
+			return nil, nil, fmt.Errorf("storage.dsn is required for the %s driver", cfg.Storage.Driver)
+		}
+		repo, err := repository.OpenSQLOrderRepository(cfg.Storage.Driver, cfg.Storage.DSN)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, func() {
+			if err := repo.Close(); err != nil {
+				logError(err, "Closing repository")
+			}
+		}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
+
+// tradingSession parses the session close time and zone from the config.
+func tradingSession(cfg *config.Config) (service.TradingSession, error) {
+	location, err := time.LoadLocation(cfg.Orders.Timezone)
+	if err != nil {
+		return service.TradingSession{}, fmt.Errorf("orders.timezone: %w", err)
+	}
+	close, err := time.Parse("15:04", cfg.Orders.SessionClose)
+	if err != nil {
+		return service.TradingSession{}, fmt.Errorf("orders.session_close must be HH:MM: %w", err)
+	}
+	return service.TradingSession{
+		CloseHour:   close.Hour(),
+		CloseMinute: close.Minute(),
+		Location:    location,
+	}, nil
+}
+
+// streamMarketData feeds the adapter's price stream for every configured
+// symbol into the service, which trails and triggers stop orders from it.
+func streamMarketData(ctx context.Context, client *adapter.Client, omsService *service.OMSService, cfg *config.Config) {
+	retry := time.Duration(cfg.MarketData.ReconnectSeconds) * time.Second
+	for _, symbol := range cfg.MarketData.Symbols {
+		go client.StreamMarketData(ctx, symbol, retry, func(tick adapter.MarketData) {
+			triggered, err := omsService.OnPrice(tick.Symbol, tick.Price)
+			if err != nil {
+				logError(err, "Applying market data")
+			}
+			for _, order := range triggered {
+				logInfo("Stop triggered", "order", order.ID, "symbol", order.Symbol, "trigger", order.TriggerPrice)
+			}
+		})
+	}
+	logInfo("Streaming market data", "symbols", cfg.MarketData.Symbols)
+}
+
+// executionRetry is the wait before an execution report that failed to apply
+// is tried again.
+const executionRetry = 5 * time.Second
+
+// consumeExecutionReports applies the broker's execution reports to OMS
+// orders. Reports that can never apply, for orders the OMS does not know or
+// moves their status does not allow, are logged and skipped; other failures
+// are retried.
+func consumeExecutionReports(ctx context.Context, consumer *kafka.ExecutionConsumer, omsService *service.OMSService) {
+	consumer.Run(ctx, executionRetry, func(report models.ExecutionReport) error {
+		order, applied, err := omsService.ApplyExecutionReport(report)
+		switch {
+		case errors.Is(err, service.ErrUnknownBrokerOrder), errors.Is(err, service.ErrInvalidTransition):
+			logError(err, "Skipping execution report")
+			return nil
+		case err != nil:
+			return err
+		case applied:
+			logInfo("Execution report applied", "order", order.ID, "broker_order", report.BrokerOrderID, "status", order.Status)
+		}
+		return nil
+	})
+}
+
+// openJournal opens the event journal and rebuilds the repository from it.
+// Only the memory store can be rebuilt this way.
+func openJournal(cfg *config.Config, repo repository.OrderRepository) (*journal.Journal, error) {
+	store, ok := repo.(*repository.InMemoryOrderRepository)
+	if !ok {
+		return nil, fmt.Errorf("the journal requires the memory storage driver, not %q", cfg.Storage.Driver)
+	}
+
+	j, err := journal.Open(cfg.Journal.Dir, store, cfg.Journal.CheckpointEvery)
+	if err != nil {
+		return nil, err
+	}
+	replayed := 0
+	err = j.Replay(func(ev models.Event) error {
+		replayed++
+		return repository.ApplyEvent(store, ev)
+	})
+	if err != nil {
+		j.Close()
+		return nil, err
+	}
+	logInfo("Replayed journal", "dir", cfg.Journal.Dir, "events", replayed)
+	return j, nil
+}
+
 // Middleware for logging
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -121,23 +356,6 @@ func validateScalperOrder(order models.ScalperOrder) error {
 	return nil
 }
 
-func validateOrder(order models.Order) error {
-	if order.Symbol == "" {
-		return fmt.Errorf("symbol is required")
-	}
-	if order.Side != "buy" && order.Side != "sell" {
-		return fmt.Errorf("side must be 'buy' or 'sell'")
-	}
-	if order.Quantity <= 0 {
-		return fmt.Errorf("quantity must be positive")
-	}
-	if order.Price <= 0 {
-		return fmt.Errorf("price must be positive")
-	}
-	// Add more validation as needed
-	return nil
-}
-
 func validatePositionOrder(order models.PositionOrder) error {
 	if order.PositionID == "" {
 		return fmt.Errorf("position ID is required")
@@ -173,4 +391,4 @@ func basicAuthMiddleware(next http.HandlerFunc, username, password string) http.
 
 		next.ServeHTTP(w, r)
 	}
-}
\ No newline at end of file
+}
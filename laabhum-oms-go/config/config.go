@@ -0,0 +1,157 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the OMS configuration
+type Config struct {
+	Server struct {
+		Address string `yaml:"address"`
+	} `yaml:"server"`
+	Auth struct {
+		// CallerSecret is shared with the gateway, which signs the caller
+		// headers of every request with it. Requests without a valid
+		// signature are refused.
+		CallerSecret string `yaml:"caller_secret"`
+	} `yaml:"auth"`
+	Storage struct {
+		// Driver selects the order repository: "memory", "postgres" or "sqlite"
+		Driver string `yaml:"driver"`
+		// DSN is the connection string for the postgres and sqlite drivers
+		DSN string `yaml:"dsn"`
+		// SnapshotPath is where the memory driver saves its contents on
+		// shutdown and restores them from on startup. Empty disables it.
+		SnapshotPath string `yaml:"snapshot_path"`
+	} `yaml:"storage"`
+	Journal struct {
+		// Dir holds the write-ahead event journal. When set, the memory store
+		// is rebuilt from it on startup and SnapshotPath is ignored.
+		Dir string `yaml:"dir"`
+		// CheckpointEvery is the number of events between checkpoints.
+		CheckpointEvery int `yaml:"checkpoint_every"`
+	} `yaml:"journal"`
+	Orders struct {
+		// SessionClose is the "HH:MM" time at which DAY orders expire
+		SessionClose string `yaml:"session_close"`
+		// Timezone is the IANA zone SessionClose is in
+		Timezone string `yaml:"timezone"`
+		// ExpiryCheckSeconds is how often working orders are checked for expiry
+		ExpiryCheckSeconds int `yaml:"expiry_check_seconds"`
+		// IdempotencyHours is how long a client order id is remembered
+		IdempotencyHours int `yaml:"idempotency_hours"`
+	} `yaml:"orders"`
+	Scalper struct {
+		// Legs is the default number of child legs a scalper order is split into
+		Legs int `yaml:"legs"`
+		// MaxChildQuantity caps the quantity of a single child leg; 0 means no cap
+		MaxChildQuantity int `yaml:"max_child_quantity"`
+	} `yaml:"scalper"`
+	Adapter struct {
+		// URL is the base URL of the broker adapter. Empty keeps conversions
+		// inside the OMS, for paper trading.
+		URL string `yaml:"url"`
+	} `yaml:"adapter"`
+	Kafka struct {
+		// Brokers receive the order lifecycle events; empty disables publishing
+		Brokers []string `yaml:"brokers"`
+		// Topic is the topic the order events are published to
+		Topic string `yaml:"topic"`
+		// ExecutionTopic is where the broker adapter publishes its order
+		// events; empty disables consuming them
+		ExecutionTopic string `yaml:"execution_topic"`
+		// GroupID is the consumer group the OMS reads execution reports in
+		GroupID string `yaml:"group_id"`
+	} `yaml:"kafka"`
+	NATS struct {
+		// URL of the NATS server that receives order events on
+		// oms.orders.<symbol>.<event> and carries order commands; empty
+		// disables NATS
+		URL string `yaml:"url"`
+	} `yaml:"nats"`
+	Outbox struct {
+		// BatchSize is the most order events relayed to the publishers at once
+		BatchSize int `yaml:"batch_size"`
+		// RetrySeconds is how often the relay retries undelivered events
+		RetrySeconds int `yaml:"retry_seconds"`
+	} `yaml:"outbox"`
+	MarketData struct {
+		// Symbols are streamed from the adapter's market data feed to trail
+		// and trigger stop orders. It needs adapter.url.
+		Symbols []string `yaml:"symbols"`
+		// ReconnectSeconds is the wait before redialing a dropped stream
+		ReconnectSeconds int `yaml:"reconnect_seconds"`
+	} `yaml:"market_data"`
+	Risk struct {
+		// Pre-trade risk limits; zero disables a limit
+		RestrictedSymbols  []string `yaml:"restricted_symbols"`
+		MaxOrderQuantity   int      `yaml:"max_order_quantity"`
+		MaxOrderNotional   float64  `yaml:"max_order_notional"`
+		PriceBandPercent   float64  `yaml:"price_band_percent"`
+		MaxPosition        int      `yaml:"max_position"`
+		MaxDailyLoss       float64  `yaml:"max_daily_loss"`
+		MaxOrdersPerSecond int      `yaml:"max_orders_per_second"`
+	} `yaml:"risk"`
+	CTC struct {
+		// CostPerUnit is a fixed cost per unit (brokerage, fees) recovered by a
+		// cover-the-cost stop
+		CostPerUnit float64 `yaml:"cost_per_unit"`
+		// CostBps is the cost in basis points of the entry price (taxes, slippage)
+		CostBps float64 `yaml:"cost_bps"`
+	} `yaml:"ctc"`
+}
+
+// Default returns the configuration used when no config file is present
+func Default() *Config {
+	var cfg Config
+	cfg.Server.Address = ":8081"
+	cfg.Storage.Driver = "memory"
+	cfg.Journal.CheckpointEvery = 1000
+	cfg.Orders.SessionClose = "15:30"
+	cfg.Orders.Timezone = "Asia/Kolkata"
+	cfg.Orders.ExpiryCheckSeconds = 30
+	cfg.Orders.IdempotencyHours = 24
+	cfg.Scalper.Legs = 1
+	cfg.MarketData.ReconnectSeconds = 5
+	cfg.Kafka.Topic = "oms.order-events"
+	cfg.Kafka.GroupID = "laabhum-oms"
+	cfg.Outbox.BatchSize = 100
+	cfg.Outbox.RetrySeconds = 5
+	return &cfg
+}
+
+// LoadConfig loads configuration from a YAML file on top of the defaults. A
+// missing file is not an error. OMS_STORAGE_DRIVER and OMS_STORAGE_DSN override
+// the storage settings and OMS_CALLER_SECRET the caller secret, so credentials
+// can stay out of the file.
+func LoadConfig(path string) (*Config, error) {
+	cfg := Default()
+
+	file, err := os.Open(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("error opening config file: %w", err)
+	default:
+		defer file.Close()
+		if err := yaml.NewDecoder(file).Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("error decoding config file: %w", err)
+		}
+	}
+
+	if driver := os.Getenv("OMS_STORAGE_DRIVER"); driver != "" {
+		cfg.Storage.Driver = driver
+	}
+	if dsn := os.Getenv("OMS_STORAGE_DSN"); dsn != "" {
+		cfg.Storage.DSN = dsn
+	}
+	if secret := os.Getenv("OMS_CALLER_SECRET"); secret != "" {
+		cfg.Auth.CallerSecret = secret
+	}
+	return cfg, nil
+}
@@ -0,0 +1,349 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+const (
+	walFile        = "wal.log"
+	checkpointFile = "checkpoint.json"
+	archiveDir     = "archive"
+)
+
+// Snapshotter is a store whose full state can be written out and read back.
+// The journal uses it to checkpoint the state it has already replayed.
+type Snapshotter interface {
+	WriteSnapshot(w io.Writer) error
+	ReadSnapshot(r io.Reader) error
+}
+
+// Journal is an append-only, write-ahead log of OMS events stored in a
+// directory:
+//
+//	wal.log          events since the last checkpoint, one JSON object per line
+//	checkpoint.json  store state as of the sequence number in its first line
+//	archive/         wal segments retired by checkpoints, kept for auditing
+//
+// Every append is fsynced before it returns. A checkpoint is taken once
+// checkpointEvery events have been appended since the previous one, which
+// keeps replay time bounded by that number.
+type Journal struct {
+	mu              sync.Mutex
+	dir             string
+	store           Snapshotter
+	checkpointEvery int
+
+	file            *os.File
+	seq             uint64 // last sequence number written
+	firstSeq        uint64 // first sequence number in wal.log
+	sinceCheckpoint int
+}
+
+// Open opens or creates a journal in dir. Call Replay before Append so the
+// journal knows where its sequence numbers left off.
+func Open(dir string, store Snapshotter, checkpointEvery int) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Join(dir, archiveDir), 0o755); err != nil {
+		return nil, fmt.Errorf("create journal dir: %w", err)
+	}
+	return &Journal{dir: dir, store: store, checkpointEvery: checkpointEvery}, nil
+}
+
+// Replay restores the store from the latest checkpoint and then feeds every
+// later event in wal.log to apply, in order. A partially written final line,
+// left by a crash mid-append, is discarded.
+func (j *Journal) Replay(apply func(models.Event) error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	checkpointSeq, err := j.loadCheckpoint()
+	if err != nil {
+		return err
+	}
+	j.seq = checkpointSeq
+
+	path := filepath.Join(j.dir, walFile)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("open wal: %w", err)
+	}
+
+	var good int64
+	reader := bufio.NewReader(file)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr == io.EOF && len(line) > 0 {
+			// Torn write: the record never reached its newline.
+			break
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			file.Close()
+			return fmt.Errorf("read wal: %w", readErr)
+		}
+
+		var ev models.Event
+		if err := json.Unmarshal(bytes.TrimSpace(line), &ev); err != nil {
+			file.Close()
+			return fmt.Errorf("decode wal record at offset %d: %w", good, err)
+		}
+		good += int64(len(line))
+
+		if ev.Seq <= checkpointSeq {
+			// Already contained in the checkpoint; happens when a crash
+			// interrupted a checkpoint before the wal was rotated.
+			continue
+		}
+		if j.firstSeq == 0 {
+			j.firstSeq = ev.Seq
+		}
+		if err := apply(ev); err != nil {
+			file.Close()
+			return fmt.Errorf("apply event %d: %w", ev.Seq, err)
+		}
+		j.seq = ev.Seq
+		j.sinceCheckpoint++
+	}
+
+	if err := file.Truncate(good); err != nil {
+		file.Close()
+		return fmt.Errorf("truncate wal: %w", err)
+	}
+	if _, err := file.Seek(good, io.SeekStart); err != nil {
+		file.Close()
+		return fmt.Errorf("seek wal: %w", err)
+	}
+	j.file = file
+	return nil
+}
+
+// Append assigns the next sequence number to ev and durably writes it. If a
+// checkpoint is due it is taken first, so the checkpoint reflects every
+// event appended and applied before this one.
+func (j *Journal) Append(ev *models.Event) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return errors.New("journal: Replay must be called before Append")
+	}
+	if j.checkpointEvery > 0 && j.sinceCheckpoint >= j.checkpointEvery {
+		if err := j.checkpoint(); err != nil {
+			return err
+		}
+	}
+
+	ev.Seq = j.seq + 1
+	if ev.Timestamp == 0 {
+		ev.Timestamp = time.Now().Unix()
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write wal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("sync wal: %w", err)
+	}
+
+	j.seq = ev.Seq
+	if j.firstSeq == 0 {
+		j.firstSeq = ev.Seq
+	}
+	j.sinceCheckpoint++
+	return nil
+}
+
+// Checkpoint writes the current store state and retires the wal into the
+// archive. It must not run concurrently with mutations of the store.
+func (j *Journal) Checkpoint() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return errors.New("journal: Replay must be called before Checkpoint")
+	}
+	return j.checkpoint()
+}
+
+func (j *Journal) checkpoint() error {
+	if j.sinceCheckpoint == 0 {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(j.dir, checkpointFile+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := json.NewEncoder(tmp).Encode(checkpointHeader{Seq: j.seq}); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write checkpoint header: %w", err)
+	}
+	if err := j.store.WriteSnapshot(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(j.dir, checkpointFile)); err != nil {
+		return fmt.Errorf("install checkpoint: %w", err)
+	}
+
+	// The checkpoint now covers everything in the wal; move it to the archive
+	// and start an empty one.
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+	archived := filepath.Join(j.dir, archiveDir, fmt.Sprintf("wal-%020d-%020d.log", j.firstSeq, j.seq))
+	if err := os.Rename(filepath.Join(j.dir, walFile), archived); err != nil {
+		return fmt.Errorf("archive wal: %w", err)
+	}
+	file, err := os.OpenFile(filepath.Join(j.dir, walFile), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open wal: %w", err)
+	}
+	j.file = file
+	j.firstSeq = 0
+	j.sinceCheckpoint = 0
+	return nil
+}
+
+// Close flushes and closes the wal.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// Events returns every journaled event about the given order, oldest first,
+// including events from archived segments.
+func (j *Journal) Events(orderID string) ([]models.Event, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	segments, err := filepath.Glob(filepath.Join(j.dir, archiveDir, "wal-*.log"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(segments)
+	segments = append(segments, filepath.Join(j.dir, walFile))
+
+	var events []models.Event
+	for _, segment := range segments {
+		err := readSegment(segment, func(ev models.Event) {
+			if concerns(ev, orderID) {
+				events = append(events, ev)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+type checkpointHeader struct {
+	Seq uint64 `json:"seq"`
+}
+
+// loadCheckpoint restores the store from checkpoint.json, if any, and returns
+// the sequence number it covers.
+func (j *Journal) loadCheckpoint() (uint64, error) {
+	file, err := os.Open(filepath.Join(j.dir, checkpointFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("open checkpoint: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	headerLine, err := reader.ReadBytes('\n')
+	if err != nil {
+		return 0, fmt.Errorf("read checkpoint header: %w", err)
+	}
+	var header checkpointHeader
+	if err := json.Unmarshal(headerLine, &header); err != nil {
+		return 0, fmt.Errorf("decode checkpoint header: %w", err)
+	}
+	if err := j.store.ReadSnapshot(reader); err != nil {
+		return 0, fmt.Errorf("restore checkpoint: %w", err)
+	}
+	return header.Seq, nil
+}
+
+// readSegment calls fn for every complete record in a wal segment.
+func readSegment(path string, fn func(models.Event)) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var ev models.Event
+		if err := json.Unmarshal(bytes.TrimSpace(line), &ev); err != nil {
+			return fmt.Errorf("decode %s: %w", filepath.Base(path), err)
+		}
+		fn(ev)
+	}
+}
+
+// concerns reports whether ev touched the given order, either directly or as
+// one of a scalper order's children.
+func concerns(ev models.Event, orderID string) bool {
+	if ev.OrderID == orderID {
+		return true
+	}
+	if ev.ScalperOrder != nil {
+		for _, child := range ev.ScalperOrder.ChildOrders {
+			if child.ID == orderID {
+				return true
+			}
+		}
+	}
+	for _, trade := range ev.Trades {
+		if trade.OrderID == orderID {
+			return true
+		}
+	}
+	return false
+}
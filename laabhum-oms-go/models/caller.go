@@ -0,0 +1,35 @@
+package models
+
+// Role says how much of the OMS a caller may see and act on.
+type Role string
+
+const (
+	RoleTrader   Role = "trader"   // Only the orders, trades and positions of its own account
+	RoleOperator Role = "operator" // Every account
+)
+
+// Caller is the account and user a request to the OMS is made for.
+type Caller struct {
+	AccountID string `json:"account_id,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+	Role      Role   `json:"role,omitempty"`
+}
+
+// IsOperator reports whether c may act across accounts.
+func (c Caller) IsOperator() bool {
+	return c.Role == RoleOperator
+}
+
+// CanAccess reports whether c may see and act on what belongs to accountID.
+func (c Caller) CanAccess(accountID string) bool {
+	return c.IsOperator() || (c.AccountID != "" && c.AccountID == accountID)
+}
+
+// Scope returns the account the queries of c are restricted to: its own for
+// a trader and, for an operator, requested, where empty means every account.
+func (c Caller) Scope(requested string) string {
+	if c.IsOperator() {
+		return requested
+	}
+	return c.AccountID
+}
@@ -0,0 +1,78 @@
+package models
+
+// EventType names a kind of mutation made through the OMS.
+type EventType string
+
+const (
+	EventOrderCreated       EventType = "order.created"
+	EventOrderRejected      EventType = "order.rejected"
+	EventOrderModified      EventType = "order.modified"
+	EventOrderCanceled      EventType = "order.canceled"
+	EventOrderAccepted      EventType = "order.accepted"
+	EventScalperCreated     EventType = "scalper.created"
+	EventScalperModified    EventType = "scalper.modified"
+	EventChildExecuted      EventType = "scalper.child_executed"
+	EventOrderFilled        EventType = "order.filled"
+	EventOrderExpired       EventType = "order.expired"
+	EventStopTrailed        EventType = "order.trailed"
+	EventStopTriggered      EventType = "order.triggered"
+	EventStopLossMoved      EventType = "order.ctc"
+	EventTradeExited        EventType = "order.exit"
+	EventPositionConverted  EventType = "position.converted"
+	EventKillSwitchEngaged  EventType = "kill_switch.engaged"
+	EventKillSwitchReleased EventType = "kill_switch.released"
+	EventOutboxRelayed      EventType = "outbox.relayed"
+)
+
+// Event is an immutable record of one mutation made through the OMS. It carries
+// the full resulting state of every entity it touched, so replaying events in
+// sequence order rebuilds the store.
+type Event struct {
+	Seq          uint64        `json:"seq"`
+	Type         EventType     `json:"type"`
+	OrderID      string        `json:"order_id"`
+	Actor        string        `json:"actor,omitempty"` // Who made the mutation: a user, "broker", or empty for the OMS itself
+	Timestamp    int64         `json:"timestamp"`
+	Order        *Order        `json:"order,omitempty"`
+	ScalperOrder *ScalperOrder `json:"scalper_order,omitempty"`
+	Trades       []Trade       `json:"trades,omitempty"`
+	KillSwitch   *KillSwitch   `json:"kill_switch,omitempty"`
+	// Idempotency is the client order id an order was created under.
+	Idempotency *IdempotencyRecord `json:"idempotency,omitempty"`
+	// OrderEvents are the lifecycle events this mutation publishes. They are
+	// stored in the outbox together with the mutation.
+	OrderEvents []OrderEvent `json:"order_events,omitempty"`
+	// RelayedEvents are the IDs of outbox events that have been delivered.
+	RelayedEvents []string `json:"relayed_events,omitempty"`
+}
+
+// OrderEventVersion is the schema version of OrderEvent. It is bumped on any
+// change that is not a pure addition of fields.
+const OrderEventVersion = 1
+
+// OrderEventKind is the lifecycle change an OrderEvent reports.
+type OrderEventKind string
+
+const (
+	OrderEventCreated   OrderEventKind = "created"
+	OrderEventModified  OrderEventKind = "modified"
+	OrderEventCanceled  OrderEventKind = "canceled" // Also sent for expired orders
+	OrderEventFilled    OrderEventKind = "filled"   // Sent for partial fills too
+	OrderEventRejected  OrderEventKind = "rejected"
+	OrderEventTriggered OrderEventKind = "triggered" // A stop's trigger price traded; the stop itself is now live
+)
+
+// OrderEvent is the form in which order lifecycle changes are published to
+// downstream consumers. There is one per changed order; Order is its full
+// state after the change and Trades are the fills that caused it, if any.
+type OrderEvent struct {
+	Version   int            `json:"version"`
+	ID        string         `json:"id"` // Unique per event, for de-duplication
+	Kind      OrderEventKind `json:"type"`
+	OrderID   string         `json:"order_id"`
+	ParentID  string         `json:"parent_id,omitempty"`
+	AccountID string         `json:"account_id,omitempty"`
+	Timestamp int64          `json:"timestamp"`
+	Order     Order          `json:"order"`
+	Trades    []Trade        `json:"trades,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+// HistoryKind says what a HistoryEntry records.
+type HistoryKind string
+
+const (
+	HistoryStatus    HistoryKind = "status"    // A status transition
+	HistoryAmendment HistoryKind = "amendment" // An accepted amendment
+	HistoryFill      HistoryKind = "fill"      // A trade booked against the order
+)
+
+// HistoryEntry is one change applied to an order. Exactly one of Transition,
+// Amendment and Trade is set, as named by Kind.
+type HistoryEntry struct {
+	Kind       HistoryKind       `json:"kind"`
+	Timestamp  int64             `json:"timestamp"`
+	Event      EventType         `json:"event,omitempty"` // The mutation that made the change, when known
+	Actor      string            `json:"actor,omitempty"` // Who made the change, when known
+	Transition *StatusTransition `json:"transition,omitempty"`
+	Amendment  *Amendment        `json:"amendment,omitempty"`
+	Trade      *Trade            `json:"trade,omitempty"`
+}
+
+// OrderHistory is every change applied to an order, oldest first.
+type OrderHistory struct {
+	OrderID string         `json:"order_id"`
+	Entries []HistoryEntry `json:"entries"`
+}
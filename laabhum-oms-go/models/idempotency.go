@@ -0,0 +1,10 @@
+package models
+
+// IdempotencyRecord remembers the order a client order id created, so that a
+// repeated submission returns that order instead of creating another.
+type IdempotencyRecord struct {
+	Key         string `json:"key"` // Account ID and client order ID
+	OrderID     string `json:"order_id"`
+	RequestHash string `json:"request_hash"` // Fingerprint of the submitted order
+	CreatedAt   int64  `json:"created_at"`
+}
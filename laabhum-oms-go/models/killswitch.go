@@ -0,0 +1,29 @@
+package models
+
+// KillSwitch blocks new orders for one account, or for the whole OMS when
+// AccountID is empty. It stays engaged until it is explicitly released.
+type KillSwitch struct {
+	AccountID string `json:"account_id,omitempty"`
+	Engaged   bool   `json:"engaged"`
+	Reason    string `json:"reason,omitempty"` // Why it was last engaged
+	UpdatedAt int64  `json:"updated_at"`
+	// Audit lists every engage and release, oldest first.
+	Audit []KillSwitchAction `json:"audit,omitempty"`
+}
+
+// KillSwitchAction is the audit record of one engage or release.
+type KillSwitchAction struct {
+	Action    string `json:"action"` // "engage" or "release"
+	Actor     string `json:"actor,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Flatten   bool   `json:"flatten,omitempty"`
+	Canceled  int    `json:"canceled,omitempty"` // Resting orders canceled
+	Exits     int    `json:"exits,omitempty"`    // Market exits generated to flatten
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Kill switch actions.
+const (
+	KillSwitchEngage  = "engage"
+	KillSwitchRelease = "release"
+)
@@ -1,38 +1,274 @@
 package models
 
+// OrderStatus is a stage in the order lifecycle. The legal moves between
+// stages are enforced by service.OMSService.
+type OrderStatus string
+
+const (
+	OrderStatusNew             OrderStatus = "new"
+	OrderStatusPending         OrderStatus = "pending"
+	OrderStatusOpen            OrderStatus = "open"
+	OrderStatusPartiallyFilled OrderStatus = "partially_filled"
+	OrderStatusFilled          OrderStatus = "filled"
+	OrderStatusCanceled        OrderStatus = "canceled"
+	OrderStatusRejected        OrderStatus = "rejected"
+	OrderStatusExpired         OrderStatus = "expired"
+)
+
+// IsTerminal reports whether no further transitions are possible from s.
+func (s OrderStatus) IsTerminal() bool {
+	switch s {
+	case OrderStatusFilled, OrderStatusCanceled, OrderStatusRejected, OrderStatusExpired:
+		return true
+	}
+	return false
+}
+
+// StatusTransition records a single lifecycle change of an order.
+type StatusTransition struct {
+	From      OrderStatus `json:"from"`
+	To        OrderStatus `json:"to"`
+	Reason    string      `json:"reason,omitempty"`
+	Event     EventType   `json:"event,omitempty"`   // The mutation that made the change
+	Actor     string      `json:"actor,omitempty"`   // Who made the change, when known
+	Version   int         `json:"version,omitempty"` // Version of the order the change produced
+	Timestamp int64       `json:"timestamp"`
+}
+
+// ProductType is the margin product an order is placed under. Intraday (MIS)
+// positions are squared off by the broker at the end of the day; delivery
+// (CNC) and carry-forward (NRML) positions are held overnight.
+type ProductType string
+
+const (
+	ProductMIS  ProductType = "MIS"
+	ProductCNC  ProductType = "CNC"
+	ProductNRML ProductType = "NRML"
+)
+
+// Valid reports whether p is one of the known product types.
+func (p ProductType) Valid() bool {
+	switch p {
+	case ProductMIS, ProductCNC, ProductNRML:
+		return true
+	}
+	return false
+}
+
+// OrderType says how an order is priced.
+type OrderType string
+
+const (
+	OrderTypeMarket    OrderType = "market"     // Fill at the best available price
+	OrderTypeLimit     OrderType = "limit"      // Fill at Price or better
+	OrderTypeStop      OrderType = "stop"       // Becomes a market order once TriggerPrice trades
+	OrderTypeStopLimit OrderType = "stop_limit" // Becomes a limit order at Price once TriggerPrice trades
+	// A stop whose TriggerPrice follows the last traded price by TrailAmount
+	// or TrailPercent as it moves favorably, and never moves back.
+	OrderTypeTrailingStop OrderType = "trailing_stop"
+)
+
+// TimeInForce says how long an order stays working.
+type TimeInForce string
+
+const (
+	TimeInForceDay TimeInForce = "DAY" // Until the end of the trading session
+	TimeInForceIOC TimeInForce = "IOC" // Fill what is possible immediately, cancel the rest
+	TimeInForceGTC TimeInForce = "GTC" // Until canceled
+	TimeInForceGTD TimeInForce = "GTD" // Until ExpiresAt
+)
+
+// OrderRole says what an order does for the position it belongs to. Orders
+// without a role are entries.
+type OrderRole string
+
+const (
+	OrderRoleEntry    OrderRole = "entry"
+	OrderRoleExit     OrderRole = "exit"
+	OrderRoleTarget   OrderRole = "target"
+	OrderRoleStopLoss OrderRole = "stop_loss"
+)
+
 type Order struct {
-	ID          string  `json:"id"`
-	Symbol      string  `json:"symbol"`
-	Quantity    int     `json:"quantity"`
-	Price       float64 `json:"price"`
-	Side        string  `json:"side"`   // "buy" or "sell"
-	Status      string  `json:"status"`
-	CreatedAt   int64   `json:"created_at"` // Optional, for tracking creation time
-	Description string  `json:"description,omitempty"` // Optional, use omitempty if not always needed
+	ID             string             `json:"id"`
+	ClientOrderID  string             `json:"client_order_id,omitempty"` // Caller's id; resubmitting it returns the original order
+	ParentID       string             `json:"parent_id,omitempty"`       // Scalper order this is a child of
+	Leg            int                `json:"leg,omitempty"`             // 1-based position among the parent's children
+	AccountID      string             `json:"account_id,omitempty"`      // Trading account placing the order
+	UserID         string             `json:"user_id,omitempty"`         // User who placed the order
+	Symbol         string             `json:"symbol"`
+	Quantity       int                `json:"quantity"`
+	FilledQuantity int                `json:"filled_quantity"`
+	AvgFillPrice   float64            `json:"avg_fill_price"` // Volume-weighted average of all fills
+	Price          float64            `json:"price"`
+	StopLoss       float64            `json:"stop_loss,omitempty"` // Protective exit price for the filled quantity
+	OrderType      OrderType          `json:"order_type,omitempty"`
+	TriggerPrice   float64            `json:"trigger_price,omitempty"` // Activation price of stop and stop-limit orders
+	TrailAmount    float64            `json:"trail_amount,omitempty"`  // Absolute trail of a trailing stop
+	TrailPercent   float64            `json:"trail_percent,omitempty"` // Trail of a trailing stop as a percentage of the price
+	Triggered      bool               `json:"triggered,omitempty"`     // A stop order's trigger price has traded
+	TimeInForce    TimeInForce        `json:"time_in_force,omitempty"`
+	ExpiresAt      int64              `json:"expires_at,omitempty"` // Expiry of GTD orders
+	Side           string             `json:"side"`                 // "buy" or "sell"
+	Product        ProductType        `json:"product,omitempty"`
+	Role           OrderRole          `json:"role,omitempty"`
+	LinkedOrderID  string             `json:"linked_order_id,omitempty"` // Entry order an exit, target or stop-loss closes
+	OCOGroupID     string             `json:"oco_group_id,omitempty"`    // Legs sharing it are one-cancels-other
+	BrokerOrderID  string             `json:"broker_order_id,omitempty"` // The broker's id for the order
+	BrokerUpdated  int64              `json:"broker_updated,omitempty"`  // Time of the last applied execution report, in Unix nanoseconds
+	Version        int                `json:"version"`                   // Incremented by every change to the order
+	Status         OrderStatus        `json:"status"`
+	CreatedAt      int64              `json:"created_at"`            // Optional, for tracking creation time
+	UpdatedAt      int64              `json:"updated_at,omitempty"`  // Time of the last status transition
+	Description    string             `json:"description,omitempty"` // Optional, use omitempty if not always needed
+	Transitions    []StatusTransition `json:"transitions,omitempty"`
+	Amendments     []Amendment        `json:"amendments,omitempty"`
+}
+
+// IsEntry reports whether the order opens a position rather than closing one.
+func (o Order) IsEntry() bool {
+	return o.Role == "" || o.Role == OrderRoleEntry
+}
+
+// RemainingQuantity is the part of the order that has not been filled yet.
+func (o Order) RemainingQuantity() int {
+	return o.Quantity - o.FilledQuantity
 }
 
+// Scalper order statuses. A scalper order's status is derived from its
+// children and never set directly.
+const (
+	ScalperStatusOpen              = "open"
+	ScalperStatusPartiallyExecuted = "partially executed"
+	ScalperStatusFullyExecuted     = "fully executed"
+	ScalperStatusCanceled          = "canceled"
+)
+
 type ScalperOrder struct {
 	ID          string  `json:"id"`
+	AccountID   string  `json:"account_id,omitempty"` // Trading account placing the order
+	UserID      string  `json:"user_id,omitempty"`    // User who placed the order
 	ParentOrder Order   `json:"parent_order"`
 	ChildOrders []Order `json:"child_orders"`
 	Status      string  `json:"status"`
 	CreatedAt   int64   `json:"created_at"`
-	Symbol      string
+	Symbol      string  `json:"symbol"`
+	Version     int     `json:"version"` // Incremented by every change to the order or its children
+
+	Quantity int `json:"quantity"`
+	// Legs optionally overrides the configured number of child legs the
+	// parent quantity is split into.
+	Legs int `json:"legs,omitempty"`
+	// Bracket, when set, makes this a bracket order: a single entry with a
+	// target and a stop-loss exit attached.
+	Bracket *Bracket `json:"bracket,omitempty"`
+}
 
-	Quantity int
+// Bracket holds the exit prices of a bracket order. The target and stop-loss
+// legs become active once the entry fills and are one-cancels-other.
+type Bracket struct {
+	TargetPrice   float64 `json:"target_price"`
+	StopLossPrice float64 `json:"stop_loss_price"` // Trigger price of the stop-loss leg
+	// TrailAmount or TrailPercent, when set, make the stop-loss leg a
+	// trailing stop that starts at StopLossPrice.
+	TrailAmount  float64 `json:"trail_amount,omitempty"`
+	TrailPercent float64 `json:"trail_percent,omitempty"`
 }
 
 type Trade struct {
-	ID        string  `json:"id"`
-	OrderID   string  `json:"order_id"`
-	Quantity  int     `json:"quantity"`
-	Price     float64 `json:"price"`
-	Timestamp int64   `json:"timestamp"`
+	ID          string      `json:"id"`
+	OrderID     string      `json:"order_id"`
+	AccountID   string      `json:"account_id,omitempty"`
+	UserID      string      `json:"user_id,omitempty"`
+	ParentID    string      `json:"parent_id,omitempty"`    // Scalper order of OrderID, if any
+	ExecutionID string      `json:"execution_id,omitempty"` // Broker execution id; unique per fill
+	Symbol      string      `json:"symbol"`
+	Side        string      `json:"side"`
+	Product     ProductType `json:"product,omitempty"`
+	Quantity    int         `json:"quantity"`
+	Price       float64     `json:"price"`
+	Timestamp   int64       `json:"timestamp"`
+	// Conversion marks the synthetic trades that move a position from one
+	// product type to another.
+	Conversion bool `json:"conversion,omitempty"`
+	// Actor made the fill, when known, and OrderVersion is the version of the
+	// order it produced.
+	Actor        string `json:"actor,omitempty"`
+	OrderVersion int    `json:"order_version,omitempty"`
+}
+
+// AmendRequest changes the terms of a working order. Fields left nil keep
+// their value. Version is the version of the order the amendment was made
+// against; it is rejected if the order has changed since.
+type AmendRequest struct {
+	Version      int      `json:"version"`
+	Quantity     *int     `json:"quantity,omitempty"`
+	Price        *float64 `json:"price,omitempty"`
+	TriggerPrice *float64 `json:"trigger_price,omitempty"`
+	StopLoss     *float64 `json:"stop_loss,omitempty"`
+	Actor        string   `json:"actor,omitempty"`
+}
+
+// Amendment records one accepted AmendRequest.
+type Amendment struct {
+	Version   int           `json:"version"` // Version of the order the amendment produced
+	Actor     string        `json:"actor,omitempty"`
+	Changes   []FieldChange `json:"changes"`
+	Timestamp int64         `json:"timestamp"`
+}
+
+// FieldChange is the change of one field in an Amendment.
+type FieldChange struct {
+	Field string  `json:"field"`
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+}
+
+// Fill is an execution reported against an order.
+type Fill struct {
+	OrderID     string  `json:"order_id"`
+	ExecutionID string  `json:"execution_id"`
+	Quantity    int     `json:"quantity"`
+	Price       float64 `json:"price"`
+	Timestamp   int64   `json:"timestamp,omitempty"`
+	Actor       string  `json:"actor,omitempty"` // Who reported the fill
 }
+
+// Position is the net holding of one account in one symbol under one product
+// type, derived from recorded trades. Quantity is positive when long and
+// negative when short.
 type Position struct {
-	Symbol string
+	ID            string      `json:"id"` // See PositionID; unique within the account
+	AccountID     string      `json:"account_id,omitempty"`
+	Symbol        string      `json:"symbol"`
+	Product       ProductType `json:"product"`
+	Quantity      int         `json:"quantity"`
+	AvgPrice      float64     `json:"avg_price"` // Average price of the open quantity
+	BuyQuantity   int         `json:"buy_quantity"`
+	SellQuantity  int         `json:"sell_quantity"`
+	RealizedPnL   float64     `json:"realized_pnl"`
+	UnrealizedPnL float64     `json:"unrealized_pnl"` // Open quantity marked at LastPrice
+	LastPrice     float64     `json:"last_price,omitempty"`
+	UpdatedAt     int64       `json:"updated_at"`
+}
 
-	Quantity int
+// PositionID identifies the position in symbol held under product.
+func PositionID(symbol string, product ProductType) string {
+	return symbol + ":" + string(product)
+}
+
+// PositionConversion moves Quantity of an open position from one product type
+// to another, e.g. MIS to NRML before the intraday square-off.
+type PositionConversion struct {
+	AccountID   string      `json:"account_id,omitempty"`
+	UserID      string      `json:"user_id,omitempty"` // User requesting the conversion
+	Symbol      string      `json:"symbol"`
+	Quantity    int         `json:"quantity"`
+	FromProduct ProductType `json:"from_product"`
+	ToProduct   ProductType `json:"to_product"`
+	// Side is the side of the position being converted: "buy" for long,
+	// "sell" for short. The OMS fills it in from its position book.
+	Side string `json:"side,omitempty"`
 }
 
 type PositionOrder struct {
@@ -45,3 +281,23 @@ type PositionOrder struct {
 	Price  float64 `json:"price,omitempty"`
 	Symbol string
 }
+
+// ExecutionStatus is the state of an order at the broker.
+type ExecutionStatus string
+
+const (
+	ExecutionAccepted ExecutionStatus = "accepted" // Working at the broker
+	ExecutionFilled   ExecutionStatus = "filled"   // Completely filled
+	ExecutionCanceled ExecutionStatus = "canceled"
+	ExecutionRejected ExecutionStatus = "rejected"
+)
+
+// ExecutionReport is the broker's account of where an order stands. Reports
+// for one order may arrive late, twice or out of order.
+type ExecutionReport struct {
+	BrokerOrderID string          `json:"broker_order_id"`
+	OrderID       string          `json:"order_id,omitempty"` // OMS order id, when the broker echoes it
+	Status        ExecutionStatus `json:"status"`
+	Price         float64         `json:"price,omitempty"`     // Execution price of a fill
+	Timestamp     int64           `json:"timestamp,omitempty"` // Broker time in Unix nanoseconds; zero when unknown
+}
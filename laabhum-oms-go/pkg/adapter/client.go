@@ -0,0 +1,46 @@
+package adapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// Client calls the broker adapter's HTTP API.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client for the adapter listening at baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    baseURL,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ConvertPosition asks the broker to move a position to another product type.
+// Any non-2xx answer is returned as an error carrying the adapter's message.
+func (c *Client) ConvertPosition(conv models.PositionConversion) error {
+	body, err := json.Marshal(conv)
+	if err != nil {
+		return err
+	}
+	resp, err := c.HTTPClient.Post(c.BaseURL+"/convert_position", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("convert position: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("convert position: adapter answered %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// MarketData is one price update from the adapter's market data stream.
+type MarketData struct {
+	Symbol string  `json:"symbol"`
+	Price  float64 `json:"price"`
+	Volume int     `json:"volume"`
+}
+
+// StreamMarketData subscribes to symbol on the adapter's /marketdata websocket
+// and calls onTick with every update until ctx is done. A dropped stream is
+// redialed after retry.
+func (c *Client) StreamMarketData(ctx context.Context, symbol string, retry time.Duration, onTick func(MarketData)) {
+	for {
+		err := c.streamMarketData(ctx, symbol, onTick)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("ERROR: market data stream for %s: %v; reconnecting in %s", symbol, err, retry)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+	}
+}
+
+func (c *Client) streamMarketData(ctx context.Context, symbol string, onTick func(MarketData)) error {
+	streamURL, err := c.marketDataURL(symbol)
+	if err != nil {
+		return err
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, streamURL, nil)
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", streamURL, err)
+	}
+	defer conn.Close()
+
+	// Unblock the read below when ctx ends.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		var tick MarketData
+		if err := conn.ReadJSON(&tick); err != nil {
+			return err
+		}
+		if tick.Symbol == "" {
+			tick.Symbol = symbol
+		}
+		onTick(tick)
+	}
+}
+
+// marketDataURL turns the adapter's http(s) base URL into the ws(s) URL of
+// the market data stream for symbol.
+func (c *Client) marketDataURL(symbol string) (string, error) {
+	u, err := url.Parse(strings.TrimSuffix(c.BaseURL, "/") + "/marketdata")
+	if err != nil {
+		return "", fmt.Errorf("adapter url: %w", err)
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	}
+	u.RawQuery = url.Values{"symbol": {symbol}}.Encode()
+	return u.String(), nil
+}
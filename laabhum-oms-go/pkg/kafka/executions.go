@@ -0,0 +1,152 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// adapterOrderEvent is the order event the broker adapter publishes, its
+// sdk.OrderEvent.
+type adapterOrderEvent struct {
+	Type  string `json:"type"`
+	Order struct {
+		ID           string    `json:"id"`
+		Price        float64   `json:"price"`
+		Status       string    `json:"status"`
+		LastModified time.Time `json:"last_modified"`
+	} `json:"order"`
+	Response struct {
+		OrderID       string `json:"order_id"`
+		BrokerOrderID string `json:"broker_order_id"`
+		Status        string `json:"status"`
+	} `json:"response"`
+}
+
+// ParseExecutionReport decodes a broker adapter order event. The broker's
+// status in the response wins over the status of the order as submitted, and
+// a cancellation event always reports a cancellation.
+func ParseExecutionReport(data []byte) (models.ExecutionReport, error) {
+	var event adapterOrderEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return models.ExecutionReport{}, err
+	}
+
+	if event.Response.BrokerOrderID == "" {
+		return models.ExecutionReport{}, errors.New("order event has no broker order id")
+	}
+	orderID := event.Response.OrderID
+	if orderID == "" {
+		orderID = event.Order.ID
+	}
+	report := models.ExecutionReport{
+		BrokerOrderID: event.Response.BrokerOrderID,
+		OrderID:       orderID,
+		Price:         event.Order.Price,
+	}
+	if !event.Order.LastModified.IsZero() {
+		report.Timestamp = event.Order.LastModified.UnixNano()
+	}
+
+	status := event.Response.Status
+	if status == "" {
+		status = event.Order.Status
+	}
+	if event.Type == "ORDER_CANCELLED" {
+		status = "CANCELLED"
+	}
+	switch strings.ToUpper(status) {
+	case "PENDING", "OPEN", "ACCEPTED":
+		report.Status = models.ExecutionAccepted
+	case "COMPLETED", "COMPLETE", "FILLED":
+		report.Status = models.ExecutionFilled
+	case "CANCELLED", "CANCELED":
+		report.Status = models.ExecutionCanceled
+	case "REJECTED":
+		report.Status = models.ExecutionRejected
+	default:
+		return report, fmt.Errorf("unknown broker order status %q", status)
+	}
+	return report, nil
+}
+
+// ExecutionConsumer reads the broker adapter's order events from Kafka as a
+// member of a consumer group.
+type ExecutionConsumer struct {
+	group sarama.ConsumerGroup
+	topic string
+}
+
+// NewExecutionConsumer joins consumer group groupID on topic. A group without
+// committed offsets starts from the oldest message, so no report is missed.
+func NewExecutionConsumer(brokers []string, groupID, topic string) (*ExecutionConsumer, error) {
+	config := sarama.NewConfig()
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	group, err := sarama.NewConsumerGroup(brokers, groupID, config)
+	if err != nil {
+		return nil, fmt.Errorf("create kafka consumer group: %w", err)
+	}
+	return &ExecutionConsumer{group: group, topic: topic}, nil
+}
+
+// Run passes each execution report to handle until ctx is done. Messages that
+// cannot be parsed are logged and skipped. A message is committed once handle
+// returns nil; after an error the consumer waits retry and delivers the
+// message again.
+func (c *ExecutionConsumer) Run(ctx context.Context, retry time.Duration, handle func(models.ExecutionReport) error) {
+	handler := executionHandler{handle: handle, retry: retry}
+	for {
+		err := c.group.Consume(ctx, []string{c.topic}, handler)
+		if ctx.Err() != nil || errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			return
+		}
+		if err != nil {
+			log.Printf("ERROR: consuming execution reports: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retry):
+			}
+		}
+	}
+}
+
+// Close leaves the consumer group.
+func (c *ExecutionConsumer) Close() error {
+	return c.group.Close()
+}
+
+type executionHandler struct {
+	handle func(models.ExecutionReport) error
+	retry  time.Duration
+}
+
+func (executionHandler) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (executionHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (h executionHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		report, err := ParseExecutionReport(msg.Value)
+		if err != nil {
+			log.Printf("ERROR: skipping execution report at %s/%d/%d: %v", msg.Topic, msg.Partition, msg.Offset, err)
+		} else if err := h.handle(report); err != nil {
+			// Ending the claim without marking the message rewinds the
+			// partition to it when the session restarts.
+			log.Printf("ERROR: execution report for broker order %s: %v", report.BrokerOrderID, err)
+			select {
+			case <-session.Context().Done():
+			case <-time.After(h.retry):
+			}
+			return err
+		}
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
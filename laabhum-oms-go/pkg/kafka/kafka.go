@@ -1,37 +1,79 @@
 package kafka
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
 
 	"github.com/IBM/sarama"
+	"github.com/Mukilan-T/laabhum-oms-go/models"
 )
 
 func SetupProducer(brokers []string) sarama.SyncProducer {
-    config := sarama.NewConfig()
-    producer, err := sarama.NewSyncProducer(brokers, config)
-    if err != nil {
-        log.Fatalf("Error creating Kafka producer: %v", err)
-    }
-    return producer
+	producer, err := sarama.NewSyncProducer(brokers, producerConfig())
+	if err != nil {
+		log.Fatalf("Error creating Kafka producer: %v", err)
+	}
+	return producer
 }
 
 func SendMessage(producer sarama.SyncProducer, topic string, message string) error {
-    msg := &sarama.ProducerMessage{
-        Topic: topic,
-        Value: sarama.StringEncoder(message),
-    }
-    _, _, err := producer.SendMessage(msg)
-    return err
+	msg := &sarama.ProducerMessage{
+		Topic: topic,
+		Value: sarama.StringEncoder(message),
+	}
+	_, _, err := producer.SendMessage(msg)
+	return err
 }
 
-func PublishOrderCreated(orderID string) {
-    log.Printf("Published 'Order Created' event for order ID: %s", orderID)
+// producerConfig waits for every in-sync replica and hashes message keys to
+// partitions, so messages with the same key stay in order.
+func producerConfig() *sarama.Config {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true // required by SyncProducer
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Partitioner = sarama.NewHashPartitioner
+	return config
 }
 
-func PublishOrderExecuted(orderID string) {
-    log.Printf("Published 'Order Executed' event for order ID: %s", orderID)
+// Publisher publishes OMS order events as JSON to a Kafka topic. Messages are
+// keyed by order id, so the events of one order are consumed in order.
+type Publisher struct {
+	producer sarama.SyncProducer
+	topic    string
 }
 
-func PublishOrderCanceled(orderID string) {
-    log.Printf("Published 'Order Canceled' event for order ID: %s", orderID)
+// NewPublisher connects to brokers and publishes to topic.
+func NewPublisher(brokers []string, topic string) (*Publisher, error) {
+	producer, err := sarama.NewSyncProducer(brokers, producerConfig())
+	if err != nil {
+		return nil, fmt.Errorf("create kafka producer: %w", err)
+	}
+	return &Publisher{producer: producer, topic: topic}, nil
+}
+
+// Publish sends events as one batch and waits until the brokers have them.
+func (p *Publisher) Publish(events []models.OrderEvent) error {
+	messages := make([]*sarama.ProducerMessage, 0, len(events))
+	for _, event := range events {
+		value, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, &sarama.ProducerMessage{
+			Topic: p.topic,
+			Key:   sarama.StringEncoder(event.OrderID),
+			Value: sarama.ByteEncoder(value),
+			Headers: []sarama.RecordHeader{
+				{Key: []byte("event-type"), Value: []byte(event.Kind)},
+				{Key: []byte("event-version"), Value: []byte(fmt.Sprint(event.Version))},
+			},
+		})
+	}
+	return p.producer.SendMessages(messages)
+}
+
+// Close flushes and closes the producer.
+func (p *Publisher) Close() error {
+	return p.producer.Close()
 }
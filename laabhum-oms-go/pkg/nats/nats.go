@@ -1,23 +1,114 @@
 package nats
 
 import (
-	"github.com/nats-io/nats.go"
+	"encoding/json"
+	"fmt"
 	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/nats-io/nats.go"
 )
 
+// Command subjects the OMS answers with request/reply.
+const (
+	SubjectCreateOrder = "oms.commands.orders.create"
+	SubjectModifyOrder = "oms.commands.orders.modify"
+	SubjectCancelOrder = "oms.commands.orders.cancel"
+)
+
+// StatusHeader carries the HTTP status code of a command reply; the reply body
+// is what the HTTP API answers for the same request.
+const StatusHeader = "Status"
+
+// commandQueue spreads commands over every OMS instance subscribed.
+const commandQueue = "oms"
+
+// flushTimeout bounds how long Publish waits for the server to acknowledge.
+const flushTimeout = 5 * time.Second
+
+// NatsClient publishes order events to NATS and serves OMS commands over it.
 type NatsClient struct {
 	conn *nats.Conn
-	ec   *nats.EncodedConn
 }
 
-func NewNatsClient(url string) *NatsClient {
-	nc, _ := nats.Connect(url)
-	ec, _ := nats.NewEncodedConn(nc, nats.JSON_ENCODER)
+// NewNatsClient connects to the NATS server at url. The connection reconnects
+// on its own after it is established.
+func NewNatsClient(url string) (*NatsClient, error) {
+	conn, err := nats.Connect(url, nats.Name("laabhum-oms"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats at %s: %w", url, err)
+	}
+	return &NatsClient{conn: conn}, nil
+}
 
-	return &NatsClient{
-		conn: nc,
-		ec:   ec,
+// OrderSubject is the subject an order event is published on,
+// oms.orders.<symbol>.<event>, e.g. oms.orders.INFY.filled. Characters NATS
+// reserves in subjects are replaced in the symbol.
+func OrderSubject(event models.OrderEvent) string {
+	symbol := strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t':
+			return '_'
+		}
+		return r
+	}, event.Order.Symbol)
+	if symbol == "" {
+		symbol = "_"
 	}
+	return "oms.orders." + symbol + "." + string(event.Kind)
+}
+
+// Publish sends each event as JSON on its OrderSubject and waits until the
+// server has them.
+func (c *NatsClient) Publish(events []models.OrderEvent) error {
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if err := c.conn.Publish(OrderSubject(event), data); err != nil {
+			return err
+		}
+	}
+	return c.conn.FlushTimeout(flushTimeout)
+}
+
+// CommandHandler answers one command request, sent with headers, with an HTTP
+// status code and a reply body.
+type CommandHandler func(request []byte, headers http.Header) (status int, reply []byte)
+
+// HandleCommand answers requests on subject with handler. Replies carry the
+// status in StatusHeader.
+func (c *NatsClient) HandleCommand(subject string, handler CommandHandler) error {
+	_, err := c.conn.QueueSubscribe(subject, commandQueue, func(msg *nats.Msg) {
+		headers := make(http.Header)
+		for key, values := range msg.Header {
+			for _, value := range values {
+				headers.Add(key, value)
+			}
+		}
+		status, body := handler(msg.Data, headers)
+		reply := nats.NewMsg(msg.Reply)
+		reply.Header.Set(StatusHeader, strconv.Itoa(status))
+		reply.Data = body
+		if err := msg.RespondMsg(reply); err != nil {
+			log.Printf("ERROR: replying to %s: %v", subject, err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("subscribe to %s: %w", subject, err)
+	}
+	return nil
+}
+
+// Close stops taking commands, lets those in flight finish and closes the
+// connection.
+func (c *NatsClient) Close() error {
+	return c.conn.Drain()
 }
 
 func ConnectNATS(url string) *nats.Conn {
@@ -0,0 +1,54 @@
+package repository
+
+import "github.com/Mukilan-T/laabhum-oms-go/models"
+
+// ApplyEvent writes the state carried by ev into repo. It is used both for
+// live mutations and when rebuilding a store from a journal, so applying the
+// same event twice must leave the store unchanged.
+func ApplyEvent(repo OrderRepository, ev models.Event) error {
+	if applier, ok := repo.(eventApplier); ok {
+		return applier.applyEvent(ev)
+	}
+	if ev.Order != nil {
+		if err := repo.SaveOrder(ev.Order); err != nil {
+			return err
+		}
+	}
+	if ev.ScalperOrder != nil {
+		if err := repo.SaveScalperOrder(ev.ScalperOrder); err != nil {
+			return err
+		}
+	}
+	if ev.KillSwitch != nil {
+		if err := repo.SaveKillSwitch(ev.KillSwitch); err != nil {
+			return err
+		}
+	}
+	if ev.Idempotency != nil {
+		if err := repo.SaveIdempotencyRecord(ev.Idempotency); err != nil {
+			return err
+		}
+	}
+	for i := range ev.Trades {
+		if err := repo.SaveTrade(&ev.Trades[i]); err != nil {
+			return err
+		}
+	}
+	if len(ev.OrderEvents) > 0 {
+		if err := repo.SaveOutbox(ev.OrderEvents); err != nil {
+			return err
+		}
+	}
+	if len(ev.RelayedEvents) > 0 {
+		if err := repo.MarkOutboxSent(ev.RelayedEvents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventApplier is implemented by stores that can apply a whole event
+// atomically, so an order mutation is never stored without its outbox events.
+type eventApplier interface {
+	applyEvent(ev models.Event) error
+}
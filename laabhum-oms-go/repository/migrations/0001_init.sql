@@ -0,0 +1,33 @@
+-- Orders, scalper orders and trades are stored as JSON documents. The columns
+-- next to the document are only the ones we filter or join on.
+
+CREATE TABLE IF NOT EXISTS orders (
+    id         TEXT PRIMARY KEY,
+    symbol     TEXT NOT NULL,
+    side       TEXT NOT NULL,
+    status     TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    data       TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_symbol ON orders (symbol);
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders (status);
+
+CREATE TABLE IF NOT EXISTS scalper_orders (
+    id         TEXT PRIMARY KEY,
+    symbol     TEXT NOT NULL,
+    status     TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    data       TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS trades (
+    id         TEXT PRIMARY KEY,
+    order_id   TEXT NOT NULL,
+    parent_id  TEXT NOT NULL DEFAULT '',
+    timestamp  BIGINT NOT NULL,
+    data       TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_trades_order_id ON trades (order_id);
+CREATE INDEX IF NOT EXISTS idx_trades_parent_id ON trades (parent_id);
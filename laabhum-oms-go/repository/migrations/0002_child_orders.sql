@@ -0,0 +1,5 @@
+-- Scalper child orders live in the orders table and point at their parent.
+
+ALTER TABLE orders ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_orders_parent_id ON orders (parent_id);
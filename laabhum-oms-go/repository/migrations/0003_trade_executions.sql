@@ -0,0 +1,5 @@
+-- Fills are idempotent on the broker execution id.
+
+ALTER TABLE trades ADD COLUMN execution_id TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trades_execution_id ON trades (execution_id) WHERE execution_id <> '';
@@ -0,0 +1,7 @@
+-- Kill switches, one per account; the empty account is the OMS-wide switch.
+
+CREATE TABLE IF NOT EXISTS kill_switches (
+    account_id TEXT PRIMARY KEY,
+    engaged    BOOLEAN NOT NULL,
+    data       TEXT NOT NULL
+);
@@ -0,0 +1,11 @@
+-- Transactional outbox: order events stored with the mutation that produced
+-- them, waiting to be relayed. seq keeps them in commit order.
+
+CREATE TABLE IF NOT EXISTS outbox (
+    seq     BIGINT NOT NULL UNIQUE,
+    id      TEXT PRIMARY KEY,
+    sent_at BIGINT NOT NULL DEFAULT 0,
+    data    TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending ON outbox (sent_at, seq);
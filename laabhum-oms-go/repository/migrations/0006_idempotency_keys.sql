@@ -0,0 +1,9 @@
+-- Client order ids already used, with the order each one created.
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key TEXT PRIMARY KEY,
+    created_at      BIGINT NOT NULL,
+    data            TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys (created_at);
@@ -0,0 +1,11 @@
+-- outbox.seq was taken as MAX(seq) + 1, which two concurrent writers can both
+-- read. It now comes from a one-row counter instead: the UPDATE that takes the
+-- next value locks the row until its transaction ends, so writers take turns
+-- on PostgreSQL and SQLite alike.
+
+CREATE TABLE IF NOT EXISTS outbox_seq (
+    id       INTEGER PRIMARY KEY CHECK (id = 1),
+    last_seq BIGINT NOT NULL
+);
+
+INSERT INTO outbox_seq (id, last_seq) SELECT 1, COALESCE(MAX(seq), 0) FROM outbox;
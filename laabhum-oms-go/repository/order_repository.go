@@ -1,94 +1,479 @@
 package repository
 
 import (
-    "errors"
-    "fmt"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
 
-    "github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/models"
 )
 
+// OrderRepository stores orders, scalper orders and trades. Scalper child
+// orders are stored as ordinary orders carrying their parent's ID: saving a
+// scalper order saves its children, and loading one loads them back.
 type OrderRepository interface {
-    CreateOrder(order models.Order) (*models.Order, error)
-    UpdateOrder(order *models.Order) error
-    GetOrders() ([]models.Order, error)
-    CreateScalperOrder(order models.ScalperOrder) (*models.ScalperOrder, error)
-    ExecuteChildOrder(parentID, childID string) error
-    GetTrades(parentID string) ([]models.Trade, error)
-    GetOrder(id string) (*models.Order, error)
-    SaveOrder(order *models.Order) error
+	CreateOrder(order models.Order) (*models.Order, error)
+	UpdateOrder(order *models.Order) error
+	// GetOrders returns every order, oldest first.
+	GetOrders() ([]models.Order, error)
+	// GetWorkingOrders returns every order not in a terminal status, oldest
+	// first.
+	GetWorkingOrders() ([]models.Order, error)
+	CreateScalperOrder(order models.ScalperOrder) (*models.ScalperOrder, error)
+	// GetScalperOrder returns ErrOrderNotFound for an unknown id.
+	GetScalperOrder(id string) (*models.ScalperOrder, error)
+	// GetScalperOrders returns every scalper order with its children, oldest
+	// first.
+	GetScalperOrders() ([]models.ScalperOrder, error)
+	SaveScalperOrder(order *models.ScalperOrder) error
+	// GetTrades returns the trades of an order, or of all children of a
+	// scalper order, oldest first.
+	GetTrades(parentID string) ([]models.Trade, error)
+	// ListTrades returns every stored trade, oldest first.
+	ListTrades() ([]models.Trade, error)
+	// SaveTrade stores a trade. Saving a trade with an existing ID is a no-op.
+	SaveTrade(trade *models.Trade) error
+	// GetTradeByExecutionID returns ErrTradeNotFound if no trade carries the
+	// given broker execution id.
+	GetTradeByExecutionID(executionID string) (*models.Trade, error)
+	// GetOrder returns ErrOrderNotFound for an unknown id.
+	GetOrder(id string) (*models.Order, error)
+	SaveOrder(order *models.Order) error
+	// GetKillSwitch returns ErrKillSwitchNotFound if the account, or the
+	// whole OMS for an empty accountID, never had a kill switch.
+	GetKillSwitch(accountID string) (*models.KillSwitch, error)
+	// GetKillSwitches returns every kill switch ordered by account.
+	GetKillSwitches() ([]models.KillSwitch, error)
+	SaveKillSwitch(ks *models.KillSwitch) error
+	// GetIdempotencyRecord returns ErrIdempotencyRecordNotFound if key was
+	// never used.
+	GetIdempotencyRecord(key string) (*models.IdempotencyRecord, error)
+	// SaveIdempotencyRecord stores rec, replacing any record under its key.
+	SaveIdempotencyRecord(rec *models.IdempotencyRecord) error
+	// DeleteIdempotencyRecords drops the records created before the given
+	// time. Records only matter for a while, so this is housekeeping and not
+	// journaled.
+	DeleteIdempotencyRecords(before int64) error
+	// SaveOutbox queues events for relaying. Events already queued, by ID,
+	// are skipped.
+	SaveOutbox(events []models.OrderEvent) error
+	// PendingOutbox returns up to limit queued events in the order they were
+	// queued.
+	PendingOutbox(limit int) ([]models.OrderEvent, error)
+	// MarkOutboxSent takes delivered events out of the queue.
+	MarkOutboxSent(ids []string) error
 }
 
+// ErrNotFound is matched by every error of a lookup that matches nothing.
+var ErrNotFound = errors.New("not found")
+
+// Lookups that match nothing return one of these, all of which are also
+// ErrNotFound.
+var (
+	ErrOrderNotFound             = fmt.Errorf("order %w", ErrNotFound)
+	ErrTradeNotFound             = fmt.Errorf("trade %w", ErrNotFound)
+	ErrKillSwitchNotFound        = fmt.Errorf("kill switch %w", ErrNotFound)
+	ErrIdempotencyRecordNotFound = fmt.Errorf("idempotency record %w", ErrNotFound)
+)
+
+// InMemoryOrderRepository keeps everything in process memory. It is safe for
+// concurrent use; values are copied in and out so callers never share state
+// with the store.
 type InMemoryOrderRepository struct {
-    orders        map[string]*models.Order
-    scalperOrders map[string]*models.ScalperOrder
-    trades        map[string][]models.Trade
+	mu            sync.RWMutex
+	orders        map[string]*models.Order
+	children      map[string]map[string]bool // order IDs keyed by parent ID; "" holds standalone orders
+	working       map[string]bool            // IDs of orders not in a terminal status
+	scalperOrders map[string]*models.ScalperOrder
+	trades        map[string][]models.Trade     // keyed by order ID
+	executions    map[string]models.Trade       // keyed by execution ID
+	killSwitches  map[string]*models.KillSwitch // keyed by account ID
+	idempotency   map[string]models.IdempotencyRecord
+	outbox        []models.OrderEvent // pending only, oldest first
 }
 
 func NewInMemoryOrderRepository() *InMemoryOrderRepository {
-    return &InMemoryOrderRepository{
-        orders:        make(map[string]*models.Order),
-        scalperOrders: make(map[string]*models.ScalperOrder),
-        trades:        make(map[string][]models.Trade),
-    }
+	return &InMemoryOrderRepository{
+		orders:        make(map[string]*models.Order),
+		children:      make(map[string]map[string]bool),
+		working:       make(map[string]bool),
+		scalperOrders: make(map[string]*models.ScalperOrder),
+		trades:        make(map[string][]models.Trade),
+		executions:    make(map[string]models.Trade),
+		killSwitches:  make(map[string]*models.KillSwitch),
+		idempotency:   make(map[string]models.IdempotencyRecord),
+	}
 }
 
 func (r *InMemoryOrderRepository) CreateOrder(order models.Order) (*models.Order, error) {
-    r.orders[order.ID] = &order
-    return &order, nil
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.putOrder(cloneOrder(&order))
+	return &order, nil
 }
 
 func (r *InMemoryOrderRepository) CreateScalperOrder(order models.ScalperOrder) (*models.ScalperOrder, error) {
-    r.scalperOrders[order.ID] = &order
-    return &order, nil
+	if err := r.SaveScalperOrder(&order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *InMemoryOrderRepository) GetScalperOrder(id string) (*models.ScalperOrder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	order, exists := r.scalperOrders[id]
+	if !exists {
+		return nil, ErrOrderNotFound
+	}
+	return r.withChildren(order), nil
+}
+
+func (r *InMemoryOrderRepository) GetScalperOrders() ([]models.ScalperOrder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var orders []models.ScalperOrder
+	for _, order := range r.scalperOrders {
+		orders = append(orders, *r.withChildren(order))
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].CreatedAt != orders[j].CreatedAt {
+			return orders[i].CreatedAt < orders[j].CreatedAt
+		}
+		return orders[i].ID < orders[j].ID
+	})
+	return orders, nil
+}
+
+// withChildren returns a copy of a stored scalper order with its children
+// attached. Callers must hold r.mu.
+func (r *InMemoryOrderRepository) withChildren(order *models.ScalperOrder) *models.ScalperOrder {
+	c := cloneScalperOrder(order)
+	c.ChildOrders = nil
+	for id := range r.children[order.ID] {
+		c.ChildOrders = append(c.ChildOrders, *cloneOrder(r.orders[id]))
+	}
+	sortChildOrders(c.ChildOrders)
+	return c
+}
+
+// putOrder stores order and indexes it under its parent and, while it is
+// working, among the working orders. Callers must hold r.mu.
+func (r *InMemoryOrderRepository) putOrder(order *models.Order) {
+	if old, ok := r.orders[order.ID]; ok && old.ParentID != order.ParentID {
+		delete(r.children[old.ParentID], order.ID)
+	}
+	r.orders[order.ID] = order
+	r.index(order)
+}
+
+// index adds order to the children and working indexes. Callers must hold
+// r.mu.
+func (r *InMemoryOrderRepository) index(order *models.Order) {
+	if r.children[order.ParentID] == nil {
+		r.children[order.ParentID] = make(map[string]bool)
+	}
+	r.children[order.ParentID][order.ID] = true
+	if order.Status.IsTerminal() {
+		delete(r.working, order.ID)
+	} else {
+		r.working[order.ID] = true
+	}
 }
 
-func (r *InMemoryOrderRepository) ExecuteChildOrder(parentID, childID string) error {
-    if parentOrder, exists := r.scalperOrders[parentID]; exists {
-        if childOrder, exists := r.scalperOrders[childID]; exists {
-            childOrder.Status = "executed"
-            parentOrder.Status = "partially executed"
-            return nil
-        }
-    }
-    return errors.New("order not found")
+func (r *InMemoryOrderRepository) SaveScalperOrder(order *models.ScalperOrder) error {
+	if order == nil || order.ID == "" {
+		return errors.New("invalid scalper order")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range order.ChildOrders {
+		child := cloneOrder(&order.ChildOrders[i])
+		child.ParentID = order.ID
+		r.putOrder(child)
+	}
+	parent := cloneScalperOrder(order)
+	parent.ChildOrders = nil
+	r.scalperOrders[order.ID] = parent
+	return nil
 }
 
 func (r *InMemoryOrderRepository) GetTrades(parentID string) ([]models.Trade, error) {
-    return r.trades[parentID], nil
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	trades := append([]models.Trade(nil), r.trades[parentID]...)
+	for id := range r.children[parentID] {
+		trades = append(trades, r.trades[id]...)
+	}
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].Timestamp < trades[j].Timestamp
+	})
+	return trades, nil
+}
+
+func (r *InMemoryOrderRepository) ListTrades() ([]models.Trade, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var trades []models.Trade
+	for _, orderTrades := range r.trades {
+		trades = append(trades, orderTrades...)
+	}
+	sort.Slice(trades, func(i, j int) bool {
+		if trades[i].Timestamp != trades[j].Timestamp {
+			return trades[i].Timestamp < trades[j].Timestamp
+		}
+		return trades[i].ID < trades[j].ID
+	})
+	return trades, nil
+}
+
+func (r *InMemoryOrderRepository) SaveTrade(trade *models.Trade) error {
+	if trade == nil || trade.ID == "" {
+		return errors.New("invalid trade")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.trades[trade.OrderID] {
+		if existing.ID == trade.ID {
+			return nil
+		}
+	}
+	r.trades[trade.OrderID] = append(r.trades[trade.OrderID], *trade)
+	if trade.ExecutionID != "" {
+		r.executions[trade.ExecutionID] = *trade
+	}
+	return nil
+}
+
+func (r *InMemoryOrderRepository) GetTradeByExecutionID(executionID string) (*models.Trade, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	trade, exists := r.executions[executionID]
+	if !exists {
+		return nil, ErrTradeNotFound
+	}
+	return &trade, nil
 }
 
 func (r *InMemoryOrderRepository) GetOrders() ([]models.Order, error) {
-    var orders []models.Order
-    for _, order := range r.orders {
-        orders = append(orders, *order)
-    }
-    return orders, nil
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var orders []models.Order
+	for _, order := range r.orders {
+		orders = append(orders, *cloneOrder(order))
+	}
+	sortOrders(orders)
+	return orders, nil
+}
+
+func (r *InMemoryOrderRepository) GetWorkingOrders() ([]models.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var orders []models.Order
+	for id := range r.working {
+		orders = append(orders, *cloneOrder(r.orders[id]))
+	}
+	sortOrders(orders)
+	return orders, nil
+}
+
+// sortOrders sorts orders oldest first.
+func sortOrders(orders []models.Order) {
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].CreatedAt != orders[j].CreatedAt {
+			return orders[i].CreatedAt < orders[j].CreatedAt
+		}
+		return orders[i].ID < orders[j].ID
+	})
 }
 
 func (r *InMemoryOrderRepository) GetOrder(id string) (*models.Order, error) {
-    order, exists := r.orders[id]
-    if !exists {
-        return nil, errors.New("order not found")
-    }
-    return order, nil
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	order, exists := r.orders[id]
+	if !exists {
+		return nil, ErrOrderNotFound
+	}
+	return cloneOrder(order), nil
 }
 
 func (r *InMemoryOrderRepository) UpdateOrder(order *models.Order) error {
-    r.orders[order.ID] = order
-    return nil
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.putOrder(cloneOrder(order))
+	return nil
 }
 
 func (r *InMemoryOrderRepository) SaveOrder(order *models.Order) error {
-    if order == nil || order.ID == "" {
-        return errors.New("invalid order")
-    }
-    r.orders[order.ID] = order
-    return nil
+	if order == nil || order.ID == "" {
+		return errors.New("invalid order")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.putOrder(cloneOrder(order))
+	return nil
+}
+
+func (r *InMemoryOrderRepository) GetKillSwitch(accountID string) (*models.KillSwitch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ks, exists := r.killSwitches[accountID]
+	if !exists {
+		return nil, ErrKillSwitchNotFound
+	}
+	return cloneKillSwitch(ks), nil
+}
+
+func (r *InMemoryOrderRepository) GetKillSwitches() ([]models.KillSwitch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var switches []models.KillSwitch
+	for _, ks := range r.killSwitches {
+		switches = append(switches, *cloneKillSwitch(ks))
+	}
+	sort.Slice(switches, func(i, j int) bool {
+		return switches[i].AccountID < switches[j].AccountID
+	})
+	return switches, nil
+}
+
+func (r *InMemoryOrderRepository) SaveKillSwitch(ks *models.KillSwitch) error {
+	if ks == nil {
+		return errors.New("invalid kill switch")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.killSwitches[ks.AccountID] = cloneKillSwitch(ks)
+	return nil
+}
+
+func (r *InMemoryOrderRepository) GetIdempotencyRecord(key string) (*models.IdempotencyRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rec, exists := r.idempotency[key]
+	if !exists {
+		return nil, ErrIdempotencyRecordNotFound
+	}
+	return &rec, nil
+}
+
+func (r *InMemoryOrderRepository) SaveIdempotencyRecord(rec *models.IdempotencyRecord) error {
+	if rec == nil || rec.Key == "" {
+		return errors.New("invalid idempotency record")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.idempotency[rec.Key] = *rec
+	return nil
+}
+
+func (r *InMemoryOrderRepository) DeleteIdempotencyRecords(before int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, rec := range r.idempotency {
+		if rec.CreatedAt < before {
+			delete(r.idempotency, key)
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryOrderRepository) SaveOutbox(events []models.OrderEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, event := range events {
+		if r.outboxIndex(event.ID) < 0 {
+			r.outbox = append(r.outbox, event)
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryOrderRepository) PendingOutbox(limit int) ([]models.OrderEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if limit > len(r.outbox) {
+		limit = len(r.outbox)
+	}
+	return append([]models.OrderEvent(nil), r.outbox[:limit]...), nil
+}
+
+// MarkOutboxSent drops the delivered events; the memory store keeps no record
+// of sent events.
+func (r *InMemoryOrderRepository) MarkOutboxSent(ids []string) error {
+	sent := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		sent[id] = true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pending := r.outbox[:0]
+	for _, event := range r.outbox {
+		if !sent[event.ID] {
+			pending = append(pending, event)
+		}
+	}
+	r.outbox = pending
+	return nil
+}
+
+// outboxIndex returns the position of event id in the outbox, or -1. Callers
+// must hold r.mu.
+func (r *InMemoryOrderRepository) outboxIndex(id string) int {
+	for i, event := range r.outbox {
+		if event.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func cloneKillSwitch(ks *models.KillSwitch) *models.KillSwitch {
+	c := *ks
+	c.Audit = append([]models.KillSwitchAction(nil), ks.Audit...)
+	return &c
+}
+
+// cloneOrder returns a deep copy of order.
+func cloneOrder(order *models.Order) *models.Order {
+	c := *order
+	c.Transitions = append([]models.StatusTransition(nil), order.Transitions...)
+	c.Amendments = append([]models.Amendment(nil), order.Amendments...)
+	return &c
+}
+
+// cloneScalperOrder returns a deep copy of order, including its children.
+func cloneScalperOrder(order *models.ScalperOrder) *models.ScalperOrder {
+	c := *order
+	c.ParentOrder = *cloneOrder(&order.ParentOrder)
+	c.ChildOrders = make([]models.Order, len(order.ChildOrders))
+	for i := range order.ChildOrders {
+		c.ChildOrders[i] = *cloneOrder(&order.ChildOrders[i])
+	}
+	if order.Bracket != nil {
+		bracket := *order.Bracket
+		c.Bracket = &bracket
+	}
+	return &c
+}
+
+// sortChildOrders puts a scalper order's children in leg order.
+func sortChildOrders(children []models.Order) {
+	sort.SliceStable(children, func(i, j int) bool {
+		if children[i].Leg != children[j].Leg {
+			return children[i].Leg < children[j].Leg
+		}
+		if children[i].CreatedAt != children[j].CreatedAt {
+			return children[i].CreatedAt < children[j].CreatedAt
+		}
+		return children[i].ID < children[j].ID
+	})
 }
 
 // SaveOrder stores the order in the database
 func SaveOrder(order map[string]interface{}) error {
-    // This is just a stub. Replace with actual DB code
-    fmt.Println("Order saved:", order)
-    return nil
-}
\ No newline at end of file
+	// This is just a stub. Replace with actual DB code
+	fmt.Println("Order saved:", order)
+	return nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// memorySnapshot is the on-disk form of an InMemoryOrderRepository.
+type memorySnapshot struct {
+	Orders        map[string]*models.Order            `json:"orders"`
+	ScalperOrders map[string]*models.ScalperOrder     `json:"scalper_orders"`
+	Trades        map[string][]models.Trade           `json:"trades"`
+	KillSwitches  map[string]*models.KillSwitch       `json:"kill_switches,omitempty"`
+	Idempotency   map[string]models.IdempotencyRecord `json:"idempotency,omitempty"`
+	Outbox        []models.OrderEvent                 `json:"outbox,omitempty"`
+}
+
+// WriteSnapshot writes the whole store as JSON to w. The store stays readable
+// while the snapshot is taken but writers wait until it is done.
+func (r *InMemoryOrderRepository) WriteSnapshot(w io.Writer) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return json.NewEncoder(w).Encode(memorySnapshot{
+		Orders:        r.orders,
+		ScalperOrders: r.scalperOrders,
+		Trades:        r.trades,
+		KillSwitches:  r.killSwitches,
+		Idempotency:   r.idempotency,
+		Outbox:        r.outbox,
+	})
+}
+
+// ReadSnapshot replaces the contents of the store with a snapshot previously
+// produced by WriteSnapshot.
+func (r *InMemoryOrderRepository) ReadSnapshot(rd io.Reader) error {
+	var snap memorySnapshot
+	if err := json.NewDecoder(rd).Decode(&snap); err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+	if snap.Orders == nil {
+		snap.Orders = make(map[string]*models.Order)
+	}
+	if snap.ScalperOrders == nil {
+		snap.ScalperOrders = make(map[string]*models.ScalperOrder)
+	}
+	if snap.Trades == nil {
+		snap.Trades = make(map[string][]models.Trade)
+	}
+	if snap.KillSwitches == nil {
+		snap.KillSwitches = make(map[string]*models.KillSwitch)
+	}
+	if snap.Idempotency == nil {
+		snap.Idempotency = make(map[string]models.IdempotencyRecord)
+	}
+
+	executions := make(map[string]models.Trade)
+	for _, trades := range snap.Trades {
+		for _, trade := range trades {
+			if trade.ExecutionID != "" {
+				executions[trade.ExecutionID] = trade
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.orders = snap.Orders
+	r.children = make(map[string]map[string]bool)
+	r.working = make(map[string]bool)
+	for _, order := range r.orders {
+		r.index(order)
+	}
+	r.scalperOrders = snap.ScalperOrders
+	r.trades = snap.Trades
+	r.executions = executions
+	r.killSwitches = snap.KillSwitches
+	r.idempotency = snap.Idempotency
+	r.outbox = snap.Outbox
+	return nil
+}
+
+// SnapshotToFile writes the store to path. The snapshot goes to a temporary
+// file first and is renamed into place, so a crash mid-write never leaves a
+// truncated snapshot behind.
+func (r *InMemoryOrderRepository) SnapshotToFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := r.WriteSnapshot(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close snapshot: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// RestoreFromFile loads a snapshot written by SnapshotToFile. A missing file
+// leaves the store empty and is not an error, so first runs need no setup.
+func (r *InMemoryOrderRepository) RestoreFromFile(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open snapshot: %w", err)
+	}
+	defer file.Close()
+	return r.ReadSnapshot(file)
+}
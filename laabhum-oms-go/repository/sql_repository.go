@@ -0,0 +1,609 @@
+package repository
+
+import (
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+
+	_ "github.com/lib/pq"  // registers the "postgres" driver
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// SQLOrderRepository is an OrderRepository backed by PostgreSQL. The same
+// schema also runs on SQLite, which is what tests and local runs use when no
+// database server is available.
+type SQLOrderRepository struct {
+	db *sql.DB
+}
+
+// OpenSQLOrderRepository connects to the database, applies any pending schema
+// migrations and returns a ready repository. driver is either "postgres" or
+// "sqlite".
+func OpenSQLOrderRepository(driver, dsn string) (*SQLOrderRepository, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open %s database: %w", driver, err)
+	}
+	if driver == "sqlite" {
+		// SQLite allows a single writer; serialising through one connection
+		// avoids "database is locked" errors under concurrent requests.
+		db.SetMaxOpenConns(1)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect to %s database: %w", driver, err)
+	}
+
+	repo := NewSQLOrderRepository(db)
+	if err := repo.Migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return repo, nil
+}
+
+// NewSQLOrderRepository wraps an already opened database. Call Migrate before
+// using it against a fresh database.
+func NewSQLOrderRepository(db *sql.DB) *SQLOrderRepository {
+	return &SQLOrderRepository{db: db}
+}
+
+// Close releases the underlying database connections.
+func (r *SQLOrderRepository) Close() error {
+	return r.db.Close()
+}
+
+// Migrate applies the embedded migrations that have not been applied yet, in
+// file name order. Each migration runs in its own transaction.
+func (r *SQLOrderRepository) Migrate() error {
+	if _, err := r.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at BIGINT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		version, err := migrationVersion(name)
+		if err != nil {
+			return err
+		}
+
+		var applied int
+		if err := r.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE version = $1`, version).Scan(&applied); err != nil {
+			return fmt.Errorf("check migration %s: %w", name, err)
+		}
+		if applied > 0 {
+			continue
+		}
+
+		script, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+
+		tx, err := r.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(string(script)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("apply migration %s: %w", name, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)`, version, time.Now().Unix()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("record migration %s: %w", name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit migration %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// migrationVersion extracts the numeric prefix of a migration file name, e.g.
+// 1 for "migrations/0001_init.sql".
+func migrationVersion(name string) (int, error) {
+	base := strings.TrimPrefix(name, "migrations/")
+	prefix, _, found := strings.Cut(base, "_")
+	if !found {
+		return 0, fmt.Errorf("migration %s: name must start with a version number", name)
+	}
+	version, err := strconv.Atoi(prefix)
+	if err != nil {
+		return 0, fmt.Errorf("migration %s: %w", name, err)
+	}
+	return version, nil
+}
+
+func (r *SQLOrderRepository) CreateOrder(order models.Order) (*models.Order, error) {
+	if err := r.SaveOrder(&order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *SQLOrderRepository) UpdateOrder(order *models.Order) error {
+	return r.SaveOrder(order)
+}
+
+func (r *SQLOrderRepository) SaveOrder(order *models.Order) error {
+	return saveOrder(r.db, order)
+}
+
+func saveOrder(db execer, order *models.Order) error {
+	if order == nil || order.ID == "" {
+		return errors.New("invalid order")
+	}
+	data, err := json.Marshal(order)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO orders (id, parent_id, symbol, side, status, created_at, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE SET
+			parent_id = excluded.parent_id,
+			symbol = excluded.symbol,
+			side = excluded.side,
+			status = excluded.status,
+			data = excluded.data`,
+		order.ID, order.ParentID, order.Symbol, order.Side, string(order.Status), order.CreatedAt, string(data))
+	return err
+}
+
+func (r *SQLOrderRepository) GetOrder(id string) (*models.Order, error) {
+	var data string
+	err := r.db.QueryRow(`SELECT data FROM orders WHERE id = $1`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var order models.Order
+	if err := json.Unmarshal([]byte(data), &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *SQLOrderRepository) GetOrders() ([]models.Order, error) {
+	return r.queryOrders(`SELECT data FROM orders ORDER BY created_at, id`)
+}
+
+func (r *SQLOrderRepository) GetWorkingOrders() ([]models.Order, error) {
+	return r.queryOrders(`SELECT data FROM orders WHERE status NOT IN ($1, $2, $3, $4) ORDER BY created_at, id`,
+		string(models.OrderStatusFilled), string(models.OrderStatusCanceled),
+		string(models.OrderStatusRejected), string(models.OrderStatusExpired))
+}
+
+func (r *SQLOrderRepository) queryOrders(query string, args ...interface{}) ([]models.Order, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []models.Order
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var order models.Order
+		if err := json.Unmarshal([]byte(data), &order); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+func (r *SQLOrderRepository) CreateScalperOrder(order models.ScalperOrder) (*models.ScalperOrder, error) {
+	if err := r.SaveScalperOrder(&order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *SQLOrderRepository) SaveScalperOrder(order *models.ScalperOrder) error {
+	if order == nil || order.ID == "" {
+		return errors.New("invalid scalper order")
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveScalperOrder(tx, order); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func saveScalperOrder(db execer, order *models.ScalperOrder) error {
+	parent := *order
+	parent.ChildOrders = nil
+	data, err := json.Marshal(parent)
+	if err != nil {
+		return err
+	}
+
+	for i := range order.ChildOrders {
+		child := order.ChildOrders[i]
+		child.ParentID = order.ID
+		if err := saveOrder(db, &child); err != nil {
+			return err
+		}
+	}
+	_, err = db.Exec(`INSERT INTO scalper_orders (id, symbol, status, created_at, data)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET
+			symbol = excluded.symbol,
+			status = excluded.status,
+			data = excluded.data`,
+		order.ID, order.Symbol, order.Status, order.CreatedAt, string(data))
+	return err
+}
+
+func (r *SQLOrderRepository) GetScalperOrder(id string) (*models.ScalperOrder, error) {
+	var data string
+	err := r.db.QueryRow(`SELECT data FROM scalper_orders WHERE id = $1`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var order models.ScalperOrder
+	if err := json.Unmarshal([]byte(data), &order); err != nil {
+		return nil, err
+	}
+	if err := r.loadChildren(&order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *SQLOrderRepository) GetScalperOrders() ([]models.ScalperOrder, error) {
+	rows, err := r.db.Query(`SELECT data FROM scalper_orders ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	var orders []models.ScalperOrder
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		var order models.ScalperOrder
+		if err := json.Unmarshal([]byte(data), &order); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Children are loaded after the parent rows are closed: SQLite runs on a
+	// single connection, which an open result set would hold.
+	for i := range orders {
+		if err := r.loadChildren(&orders[i]); err != nil {
+			return nil, err
+		}
+	}
+	return orders, nil
+}
+
+func (r *SQLOrderRepository) loadChildren(order *models.ScalperOrder) error {
+	children, err := r.queryOrders(`SELECT data FROM orders WHERE parent_id = $1`, order.ID)
+	if err != nil {
+		return err
+	}
+	sortChildOrders(children)
+	order.ChildOrders = children
+	return nil
+}
+
+func (r *SQLOrderRepository) GetTrades(parentID string) ([]models.Trade, error) {
+	return r.queryTrades(`SELECT data FROM trades WHERE parent_id = $1 OR order_id = $1 ORDER BY timestamp, id`, parentID)
+}
+
+func (r *SQLOrderRepository) ListTrades() ([]models.Trade, error) {
+	return r.queryTrades(`SELECT data FROM trades ORDER BY timestamp, id`)
+}
+
+func (r *SQLOrderRepository) SaveTrade(trade *models.Trade) error {
+	return saveTrade(r.db, trade)
+}
+
+func saveTrade(db execer, trade *models.Trade) error {
+	if trade == nil || trade.ID == "" {
+		return errors.New("invalid trade")
+	}
+	data, err := json.Marshal(trade)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO trades (id, order_id, parent_id, execution_id, timestamp, data)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO NOTHING`,
+		trade.ID, trade.OrderID, trade.ParentID, trade.ExecutionID, trade.Timestamp, string(data))
+	return err
+}
+
+func (r *SQLOrderRepository) GetTradeByExecutionID(executionID string) (*models.Trade, error) {
+	trades, err := r.queryTrades(`SELECT data FROM trades WHERE execution_id = $1`, executionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(trades) == 0 {
+		return nil, ErrTradeNotFound
+	}
+	return &trades[0], nil
+}
+
+func (r *SQLOrderRepository) queryTrades(query string, args ...interface{}) ([]models.Trade, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trades []models.Trade
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var trade models.Trade
+		if err := json.Unmarshal([]byte(data), &trade); err != nil {
+			return nil, err
+		}
+		trades = append(trades, trade)
+	}
+	return trades, rows.Err()
+}
+
+func (r *SQLOrderRepository) GetKillSwitch(accountID string) (*models.KillSwitch, error) {
+	switches, err := r.queryKillSwitches(`SELECT data FROM kill_switches WHERE account_id = $1`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(switches) == 0 {
+		return nil, ErrKillSwitchNotFound
+	}
+	return &switches[0], nil
+}
+
+func (r *SQLOrderRepository) GetKillSwitches() ([]models.KillSwitch, error) {
+	return r.queryKillSwitches(`SELECT data FROM kill_switches ORDER BY account_id`)
+}
+
+func (r *SQLOrderRepository) SaveKillSwitch(ks *models.KillSwitch) error {
+	return saveKillSwitch(r.db, ks)
+}
+
+func saveKillSwitch(db execer, ks *models.KillSwitch) error {
+	if ks == nil {
+		return errors.New("invalid kill switch")
+	}
+	data, err := json.Marshal(ks)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO kill_switches (account_id, engaged, data)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (account_id) DO UPDATE SET
+			engaged = excluded.engaged,
+			data = excluded.data`,
+		ks.AccountID, ks.Engaged, string(data))
+	return err
+}
+
+func (r *SQLOrderRepository) queryKillSwitches(query string, args ...interface{}) ([]models.KillSwitch, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var switches []models.KillSwitch
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var ks models.KillSwitch
+		if err := json.Unmarshal([]byte(data), &ks); err != nil {
+			return nil, err
+		}
+		switches = append(switches, ks)
+	}
+	return switches, rows.Err()
+}
+
+func (r *SQLOrderRepository) GetIdempotencyRecord(key string) (*models.IdempotencyRecord, error) {
+	var data string
+	err := r.db.QueryRow(`SELECT data FROM idempotency_keys WHERE idempotency_key = $1`, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrIdempotencyRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rec models.IdempotencyRecord
+	if err := json.Unmarshal([]byte(data), &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *SQLOrderRepository) SaveIdempotencyRecord(rec *models.IdempotencyRecord) error {
+	return saveIdempotencyRecord(r.db, rec)
+}
+
+func saveIdempotencyRecord(db execer, rec *models.IdempotencyRecord) error {
+	if rec == nil || rec.Key == "" {
+		return errors.New("invalid idempotency record")
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO idempotency_keys (idempotency_key, created_at, data)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (idempotency_key) DO UPDATE SET
+			created_at = excluded.created_at,
+			data = excluded.data`,
+		rec.Key, rec.CreatedAt, string(data))
+	return err
+}
+
+func (r *SQLOrderRepository) DeleteIdempotencyRecords(before int64) error {
+	_, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE created_at < $1`, before)
+	return err
+}
+
+func (r *SQLOrderRepository) SaveOutbox(events []models.OrderEvent) error {
+	return saveOutbox(r.db, events)
+}
+
+func saveOutbox(db execer, events []models.OrderEvent) error {
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		// Taking the number locks the counter until the transaction ends,
+		// so concurrent writers cannot both take it. Duplicates skipped by
+		// ON CONFLICT leave a gap, which only ordering relies on.
+		var seq int64
+		err = db.QueryRow(`UPDATE outbox_seq SET last_seq = last_seq + 1 WHERE id = 1 RETURNING last_seq`).Scan(&seq)
+		if err != nil {
+			return err
+		}
+		_, err = db.Exec(`INSERT INTO outbox (seq, id, data) VALUES ($1, $2, $3)
+			ON CONFLICT (id) DO NOTHING`,
+			seq, event.ID, string(data))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *SQLOrderRepository) PendingOutbox(limit int) ([]models.OrderEvent, error) {
+	rows, err := r.db.Query(`SELECT data FROM outbox WHERE sent_at = 0 ORDER BY seq LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.OrderEvent
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var event models.OrderEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// MarkOutboxSent keeps sent events, stamped with the time they were marked,
+// so the table doubles as a delivery log.
+func (r *SQLOrderRepository) MarkOutboxSent(ids []string) error {
+	return markOutboxSent(r.db, ids)
+}
+
+func markOutboxSent(db execer, ids []string) error {
+	now := time.Now().Unix()
+	for _, id := range ids {
+		if _, err := db.Exec(`UPDATE outbox SET sent_at = $1 WHERE id = $2 AND sent_at = 0`, now, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyEvent applies ev in a single transaction, so the orders it changes and
+// the events it queues in the outbox are stored together or not at all.
+func (r *SQLOrderRepository) applyEvent(ev models.Event) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if ev.Order != nil {
+		if err := saveOrder(tx, ev.Order); err != nil {
+			return err
+		}
+	}
+	if ev.ScalperOrder != nil {
+		if ev.ScalperOrder.ID == "" {
+			return errors.New("invalid scalper order")
+		}
+		if err := saveScalperOrder(tx, ev.ScalperOrder); err != nil {
+			return err
+		}
+	}
+	if ev.KillSwitch != nil {
+		if err := saveKillSwitch(tx, ev.KillSwitch); err != nil {
+			return err
+		}
+	}
+	if ev.Idempotency != nil {
+		if err := saveIdempotencyRecord(tx, ev.Idempotency); err != nil {
+			return err
+		}
+	}
+	for i := range ev.Trades {
+		if err := saveTrade(tx, &ev.Trades[i]); err != nil {
+			return err
+		}
+	}
+	if err := saveOutbox(tx, ev.OrderEvents); err != nil {
+		return err
+	}
+	if err := markOutboxSent(tx, ev.RelayedEvents); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// ErrAccessDenied is returned when a caller acts on an order, position or
+// kill switch of another account.
+var ErrAccessDenied = errors.New("access denied: belongs to another account")
+
+// Authorize checks that caller may see and act on the order or scalper order
+// id. Operators may act on every order. An id that names no order passes, so
+// the action itself reports it missing.
+func (s *OMSService) Authorize(caller models.Caller, id string) error {
+	if caller.IsOperator() || id == "" {
+		return nil
+	}
+	accountID, ok := s.owner(id)
+	if ok && !caller.CanAccess(accountID) {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+// AuthorizeAccount checks that caller may act on accountID, where an empty
+// accountID is the whole OMS and only open to operators.
+func (s *OMSService) AuthorizeAccount(caller models.Caller, accountID string) error {
+	if !caller.CanAccess(accountID) {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+// owner returns the account of the scalper order or order id.
+func (s *OMSService) owner(id string) (string, bool) {
+	if parent, err := s.repo.GetScalperOrder(id); err == nil {
+		return parent.AccountID, true
+	}
+	if order, err := s.repo.GetOrder(id); err == nil {
+		return order.AccountID, true
+	}
+	return "", false
+}
+
+// inAccount matches the orders of accountID, or every order when accountID
+// is empty.
+func inAccount(accountID string) func(models.Order) bool {
+	return func(order models.Order) bool {
+		return accountID == "" || order.AccountID == accountID
+	}
+}
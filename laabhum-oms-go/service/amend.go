@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// ErrVersionConflict is returned when a change is made against a version of
+// an order that is no longer current.
+var ErrVersionConflict = conflictf("order version conflict")
+
+// AmendOrder changes the quantity and prices of a working order or scalper
+// child and records the change in the order's amendment history. The amended
+// order must still be valid for its type, and the quantity may not drop to or
+// below what has filled; cancel the order instead. An amendment that changes
+// the quantity, price or trigger price must pass the kill switches and risk
+// checks again, as a new order would. An amendment that changes nothing
+// leaves the order and its version as they are.
+func (s *OMSService) AmendOrder(parentID, orderID string, req models.AmendRequest) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, err := s.lookupOrder(parentID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(order.ID, order.Version, req.Version); err != nil {
+		return nil, err
+	}
+	if order.Status.IsTerminal() {
+		return nil, fmt.Errorf("%w: order is already %s", ErrInvalidTransition, order.Status)
+	}
+
+	var changes []models.FieldChange
+	amend := func(field string, value *float64, to *float64) {
+		if to != nil && *to != *value {
+			changes = append(changes, models.FieldChange{Field: field, From: *value, To: *to})
+			*value = *to
+		}
+	}
+	if req.Quantity != nil && *req.Quantity != order.Quantity {
+		if order.OCOGroupID != "" {
+			return nil, invalidf("the quantity of a bracket leg follows its entry")
+		}
+		if *req.Quantity <= order.FilledQuantity {
+			return nil, invalidf("quantity %d must exceed the filled quantity %d", *req.Quantity, order.FilledQuantity)
+		}
+		changes = append(changes, models.FieldChange{Field: "quantity", From: float64(order.Quantity), To: float64(*req.Quantity)})
+		order.Quantity = *req.Quantity
+	}
+	amend("price", &order.Price, req.Price)
+	if req.TriggerPrice != nil && order.Triggered && *req.TriggerPrice != order.TriggerPrice {
+		return nil, invalidf("the trigger price of a triggered stop cannot change")
+	}
+	amend("trigger_price", &order.TriggerPrice, req.TriggerPrice)
+	amend("stop_loss", &order.StopLoss, req.StopLoss)
+	if len(changes) == 0 {
+		return order, nil
+	}
+
+	// normalizeTerms resets fields only the OMS sets, so check a copy.
+	now := time.Now().Unix()
+	terms := *order
+	if err := normalizeTerms(&terms, now); err != nil {
+		return nil, err
+	}
+	if order.StopLoss < 0 {
+		return nil, invalidf("stop loss must not be negative")
+	}
+	// A stop loss alone changes nothing that trades.
+	if len(changes) > 1 || changes[0].Field != "stop_loss" {
+		if err := s.checkRisk(terms, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	order.UpdatedAt = now
+	order.Amendments = append(order.Amendments, models.Amendment{
+		Version:   order.Version + 1,
+		Actor:     req.Actor,
+		Changes:   changes,
+		Timestamp: now,
+	})
+	if err := s.commitOrder(models.Event{Type: models.EventOrderModified, Actor: req.Actor, Order: order}); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// checkVersion rejects a change made against version expected of an order
+// that is at version current.
+func checkVersion(id string, current, expected int) error {
+	if expected == 0 {
+		return invalidf("the version of order %s being changed is required", id)
+	}
+	if expected != current {
+		return fmt.Errorf("%w: order %s is at version %d, not %d", ErrVersionConflict, id, current, expected)
+	}
+	return nil
+}
+
+// stampVersions moves every order ev changes to the version after the stored
+// one. Orders ev leaves unchanged keep their version and new orders start at
+// 1. A scalper order changes with any of its children. The status transitions
+// ev adds are stamped with its type, actor and the new version, and so are its
+// trades. Callers must hold s.mu.
+func (s *OMSService) stampVersions(ev models.Event) error {
+	for i := range ev.Trades {
+		ev.Trades[i].Actor = ev.Actor
+	}
+	if ev.Order != nil {
+		if err := s.stampOrder(ev.Order, ev); err != nil {
+			return err
+		}
+	}
+	if ev.ScalperOrder == nil {
+		return nil
+	}
+	for i := range ev.ScalperOrder.ChildOrders {
+		if err := s.stampOrder(&ev.ScalperOrder.ChildOrders[i], ev); err != nil {
+			return err
+		}
+	}
+	// A lookup error means the order is new to the repository.
+	prev, _ := s.repo.GetScalperOrder(ev.ScalperOrder.ID)
+	if prev == nil {
+		ev.ScalperOrder.Version = 1
+		return nil
+	}
+	ev.ScalperOrder.Version = prev.Version
+	changed, err := differs(prev, ev.ScalperOrder)
+	if changed {
+		ev.ScalperOrder.Version++
+	}
+	return err
+}
+
+func (s *OMSService) stampOrder(order *models.Order, ev models.Event) error {
+	prev, _ := s.repo.GetOrder(order.ID)
+	added := order.Transitions
+	order.Version = 1
+	if prev != nil {
+		added = added[minInt(len(prev.Transitions), len(added)):]
+		order.Version = prev.Version
+		changed, err := differs(prev, order)
+		if err != nil {
+			return err
+		}
+		if changed {
+			order.Version++
+		}
+	}
+
+	for i := range added {
+		added[i].Event = ev.Type
+		added[i].Actor = ev.Actor
+		added[i].Version = order.Version
+	}
+	for i := range ev.Trades {
+		if ev.Trades[i].OrderID == order.ID {
+			ev.Trades[i].OrderVersion = order.Version
+		}
+	}
+	return nil
+}
+
+// differs reports whether a and b encode to different JSON.
+func differs(a, b interface{}) (bool, error) {
+	before, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	after, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(before, after), nil
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/google/uuid"
+)
+
+// validateBracket checks that the target and stop-loss sit on the correct
+// sides of each other and of a limit entry price, and that a trailing
+// stop-loss has a single trail.
+func validateBracket(order models.ScalperOrder) error {
+	bracket := order.Bracket
+	if bracket.TargetPrice <= 0 || bracket.StopLossPrice <= 0 {
+		return invalidf("bracket target and stop-loss prices must be positive")
+	}
+	if bracket.TrailAmount < 0 || bracket.TrailPercent < 0 || bracket.TrailPercent >= 100 {
+		return invalidf("bracket trail must be a positive amount or a percentage below 100")
+	}
+	if bracket.TrailAmount > 0 && bracket.TrailPercent > 0 {
+		return invalidf("a bracket trails by either trail_amount or trail_percent, not both")
+	}
+	entry := order.ParentOrder.Price
+	if order.ParentOrder.Side == "sell" {
+		if bracket.TargetPrice >= bracket.StopLossPrice || (entry > 0 && (entry <= bracket.TargetPrice || entry >= bracket.StopLossPrice)) {
+			return invalidf("a sell bracket needs target < entry price < stop-loss")
+		}
+		return nil
+	}
+	if bracket.TargetPrice <= bracket.StopLossPrice || (entry > 0 && (entry >= bracket.TargetPrice || entry <= bracket.StopLossPrice)) {
+		return invalidf("a buy bracket needs stop-loss < entry price < target")
+	}
+	return nil
+}
+
+// bracketLegs returns the target and stop-loss legs for entry. They are
+// created inactive, in status new, and sized to the entry until it fills.
+func bracketLegs(entry models.Order, bracket models.Bracket, now int64) []models.Order {
+	group := uuid.NewString()
+	leg := func(role models.OrderRole, orderType models.OrderType) models.Order {
+		return models.Order{
+			ID:            uuid.NewString(),
+			ParentID:      entry.ParentID,
+			Leg:           entry.Leg,
+			AccountID:     entry.AccountID,
+			UserID:        entry.UserID,
+			Symbol:        entry.Symbol,
+			Quantity:      entry.Quantity,
+			OrderType:     orderType,
+			TimeInForce:   legTimeInForce(entry.TimeInForce),
+			ExpiresAt:     entry.ExpiresAt,
+			Side:          oppositeSide(entry.Side),
+			Product:       entry.Product,
+			Role:          role,
+			LinkedOrderID: entry.ID,
+			OCOGroupID:    group,
+			Status:        models.OrderStatusNew,
+			CreatedAt:     now,
+		}
+	}
+	target := leg(models.OrderRoleTarget, models.OrderTypeLimit)
+	target.Price = bracket.TargetPrice
+	stop := leg(models.OrderRoleStopLoss, models.OrderTypeStop)
+	stop.TriggerPrice = bracket.StopLossPrice
+	if bracket.TrailAmount > 0 || bracket.TrailPercent > 0 {
+		stop.OrderType = models.OrderTypeTrailingStop
+		stop.TrailAmount = bracket.TrailAmount
+		stop.TrailPercent = bracket.TrailPercent
+	}
+	return []models.Order{target, stop}
+}
+
+// legTimeInForce is the validity of the legs of an entry valid for tif. The
+// legs protect what the entry fills, so they last as long as it does, but at
+// least the day: an IOC stop-loss leg would be canceled before it could
+// trigger.
+func legTimeInForce(tif models.TimeInForce) models.TimeInForce {
+	if tif == models.TimeInForceIOC {
+		return models.TimeInForceDay
+	}
+	return tif
+}
+
+// settle brings the derived parts of a scalper order up to date after its
+// children changed: one-cancels-other legs first, then the parent status.
+func settle(parent *models.ScalperOrder) error {
+	if err := reconcileOCO(parent); err != nil {
+		return err
+	}
+	deriveScalperStatus(parent)
+	return nil
+}
+
+// reconcileOCO keeps the one-cancels-other legs of every entry in line with
+// the quantity the entry has left open:
+//
+//   - before the entry fills, inactive legs follow the entry's quantity, and
+//     are canceled if the entry ends without a fill;
+//   - once it fills, the legs are activated and each is sized to close the
+//     quantity still open, so a partial fill on one leg shrinks the other;
+//   - when nothing is left open, the remaining legs are canceled.
+func reconcileOCO(parent *models.ScalperOrder) error {
+	for i := range parent.ChildOrders {
+		entry := parent.ChildOrders[i]
+		if !entry.IsEntry() {
+			continue
+		}
+
+		var legs []*models.Order
+		open := entry.FilledQuantity
+		for j := range parent.ChildOrders {
+			closing := &parent.ChildOrders[j]
+			if closing.LinkedOrderID != entry.ID || closing.IsEntry() {
+				continue
+			}
+			open -= closing.FilledQuantity
+			if closing.OCOGroupID != "" && !closing.Status.IsTerminal() {
+				legs = append(legs, closing)
+			}
+		}
+
+		for _, leg := range legs {
+			var err error
+			switch {
+			case entry.FilledQuantity == 0 && entry.Status.IsTerminal():
+				err = transition(leg, models.OrderStatusCanceled, fmt.Sprintf("entry %s ended without a fill", entry.Status))
+			case entry.FilledQuantity == 0:
+				leg.Quantity = entry.Quantity
+			case open <= 0:
+				err = transition(leg, models.OrderStatusCanceled, "one-cancels-other: position closed")
+			default:
+				leg.Quantity = leg.FilledQuantity + open
+				if leg.Status == models.OrderStatusNew {
+					err = transition(leg, models.OrderStatusPending, "entry filled, leg activated")
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isOCOLeg reports whether order is a linked target or stop-loss leg.
+func isOCOLeg(order models.Order) bool {
+	return order.OCOGroupID != ""
+}
+
+// moveStopLegs moves the trigger of entry's working stop-loss legs to the
+// entry's stop-loss price. A leg that already trails past it stays put.
+func moveStopLegs(parent *models.ScalperOrder, entry *models.Order) {
+	for i := range parent.ChildOrders {
+		leg := &parent.ChildOrders[i]
+		if leg.LinkedOrderID == entry.ID && leg.Role == models.OrderRoleStopLoss && !leg.Status.IsTerminal() && tightens(*leg, entry.StopLoss) {
+			leg.TriggerPrice = entry.StopLoss
+			leg.UpdatedAt = entry.UpdatedAt
+		}
+	}
+}
+
+// ModifyScalperQuantity changes the total quantity of a bracket order. The
+// entry is resized, and its legs follow through reconcileOCO. The quantity
+// may not drop below what has already been filled; dropping it to exactly
+// that cancels the rest of the entry. version is the version of the bracket
+// order the change was made against.
+func (s *OMSService) ModifyScalperQuantity(parentID string, quantity, version int) (*models.ScalperOrder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parentOrder, err := s.repo.GetScalperOrder(parentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(parentID, parentOrder.Version, version); err != nil {
+		return nil, err
+	}
+	if parentOrder.Bracket == nil {
+		return nil, invalidf("only bracket orders can be resized as a whole")
+	}
+	var entry *models.Order
+	for i := range parentOrder.ChildOrders {
+		if parentOrder.ChildOrders[i].IsEntry() {
+			entry = &parentOrder.ChildOrders[i]
+			break
+		}
+	}
+	if entry == nil {
+		return nil, invalidf("bracket order has no entry")
+	}
+	if entry.Status.IsTerminal() {
+		return nil, fmt.Errorf("%w: entry is already %s", ErrInvalidTransition, entry.Status)
+	}
+	if quantity < entry.FilledQuantity || quantity <= 0 {
+		return nil, invalidf("quantity %d is below the filled quantity %d", quantity, entry.FilledQuantity)
+	}
+
+	entry.Quantity = quantity
+	if quantity == entry.FilledQuantity {
+		if err := transition(entry, models.OrderStatusCanceled, "remaining quantity removed by resize"); err != nil {
+			return nil, err
+		}
+	}
+	parentOrder.Quantity = quantity
+	parentOrder.ParentOrder.Quantity = quantity
+	if err := settle(parentOrder); err != nil {
+		return nil, err
+	}
+	if err := s.commit(models.Event{Type: models.EventScalperModified, OrderID: parentID, ScalperOrder: parentOrder}); err != nil {
+		return nil, err
+	}
+	return parentOrder, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/google/uuid"
+)
+
+// BrokerAdapter forwards to the broker the requests only it can carry out.
+type BrokerAdapter interface {
+	ConvertPosition(conv models.PositionConversion) error
+}
+
+// WithBrokerAdapter makes the service forward position conversions to b.
+// Without one, conversions are only booked in the OMS, which suits paper
+// trading.
+func WithBrokerAdapter(b BrokerAdapter) Option {
+	return func(s *OMSService) {
+		s.broker = b
+	}
+}
+
+// ConvertPosition moves part or all of an open position to another product
+// type. The quantity is checked against the position book, the conversion is
+// forwarded to the broker, and once the broker accepts it the move is booked
+// as a pair of conversion trades at the position's average price, so no P&L
+// is realized. It returns the source and destination positions afterwards.
+//
+// The broker is called without holding s.mu, so the position is checked
+// again before the trades are booked. If it no longer covers the conversion
+// the broker and the OMS disagree, and ConvertPosition returns ErrConflict.
+func (s *OMSService) ConvertPosition(conv models.PositionConversion) ([]models.Position, error) {
+	s.mu.Lock()
+	_, err := s.convertible(&conv)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	fromID := models.PositionID(conv.Symbol, conv.FromProduct)
+	if s.broker != nil {
+		if err := s.broker.ConvertPosition(conv); err != nil {
+			return nil, fmt.Errorf("broker rejected conversion of %s: %w", fromID, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	side := conv.Side
+	position, err := s.convertible(&conv)
+	if err == nil && conv.Side != side {
+		err = fmt.Errorf("position %s reversed", fromID)
+	}
+	if err != nil {
+		return nil, conflictf("position %s changed while the broker converted it: %v", fromID, err)
+	}
+
+	now := time.Now().Unix()
+	conversionID := uuid.NewString()
+	trade := func(product models.ProductType, side, leg string) models.Trade {
+		return models.Trade{
+			ID:          uuid.NewString(),
+			AccountID:   conv.AccountID,
+			UserID:      conv.UserID,
+			ExecutionID: "convert-" + conversionID + "-" + leg,
+			Symbol:      conv.Symbol,
+			Side:        side,
+			Product:     product,
+			Quantity:    conv.Quantity,
+			Price:       position.AvgPrice,
+			Timestamp:   now,
+			Conversion:  true,
+		}
+	}
+	trades := []models.Trade{
+		trade(conv.FromProduct, oppositeSide(conv.Side), "out"),
+		trade(conv.ToProduct, conv.Side, "in"),
+	}
+	if err := s.commit(models.Event{Type: models.EventPositionConverted, OrderID: fromID, Trades: trades}); err != nil {
+		return nil, err
+	}
+
+	var positions []models.Position
+	for _, id := range []string{fromID, models.PositionID(conv.Symbol, conv.ToProduct)} {
+		if p, ok := s.positions.Position(conv.AccountID, id, s.prices); ok {
+			positions = append(positions, p)
+		}
+	}
+	return positions, nil
+}
+
+// convertible checks conv against the position book, sets its Side from the
+// position it converts and returns that position. Callers must hold s.mu.
+func (s *OMSService) convertible(conv *models.PositionConversion) (models.Position, error) {
+	if conv.Symbol == "" {
+		return models.Position{}, invalidf("symbol is required")
+	}
+	if !conv.FromProduct.Valid() || !conv.ToProduct.Valid() {
+		return models.Position{}, invalidf("unknown product type conversion %q -> %q", conv.FromProduct, conv.ToProduct)
+	}
+	if conv.FromProduct == conv.ToProduct {
+		return models.Position{}, invalidf("position is already under that product type")
+	}
+	if conv.Quantity <= 0 {
+		return models.Position{}, invalidf("quantity must be positive")
+	}
+
+	fromID := models.PositionID(conv.Symbol, conv.FromProduct)
+	position, ok := s.positions.Position(conv.AccountID, fromID, s.prices)
+	if !ok || position.Quantity == 0 {
+		return models.Position{}, notFoundf("no open position %s", fromID)
+	}
+	if conv.Quantity > absInt(position.Quantity) {
+		return models.Position{}, invalidf("cannot convert %d of position %s, only %d open", conv.Quantity, fromID, absInt(position.Quantity))
+	}
+	conv.Side = "buy"
+	if position.Quantity < 0 {
+		conv.Side = "sell"
+	}
+	return position, nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+)
+
+// ErrNotInProfit is returned when CTC is requested for a position whose last
+// traded price has not yet moved past its cost.
+var ErrNotInProfit = conflictf("position is not in profit")
+
+// CTCCosts describes the trading costs a cover-the-cost stop must recover:
+// a fixed amount per unit plus a fraction, in basis points, of the entry price.
+type CTCCosts struct {
+	PerUnit float64
+	Bps     float64
+}
+
+// WithCTCCosts sets the costs added to the entry price when moving a stop-loss
+// to cost.
+func WithCTCCosts(costs CTCCosts) Option {
+	return func(s *OMSService) {
+		s.ctcCosts = costs
+	}
+}
+
+// Prices returns the book of last traded prices used for CTC decisions.
+func (s *OMSService) Prices() *PriceBook {
+	return s.prices
+}
+
+// CTCChildOrder moves the stop-loss of one filled child to its average entry
+// price plus costs, along with the trigger of any bracket stop-loss leg. A
+// child without a leg is exited by OnPrice once its stop-loss trades. ltp,
+// when positive, is used as the last traded price instead of the one in the
+// price book.
+func (s *OMSService) CTCChildOrder(parentID, childID string, ltp float64) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parentOrder, err := s.repo.GetScalperOrder(parentID)
+	if err != nil {
+		return nil, err
+	}
+	child := findChild(parentOrder, childID)
+	if child == nil {
+		return nil, repository.ErrOrderNotFound
+	}
+	if !child.IsEntry() {
+		return nil, invalidf("order %s is a %s order; CTC applies to entries", child.ID, child.Role)
+	}
+	if ltp <= 0 {
+		ltp, _ = s.prices.LastPrice(child.Symbol)
+	}
+	if err := s.coverCost(child, ltp); err != nil {
+		return nil, err
+	}
+	moveStopLegs(parentOrder, child)
+	if err := s.commit(models.Event{Type: models.EventStopLossMoved, OrderID: childID, ScalperOrder: parentOrder}); err != nil {
+		return nil, err
+	}
+	return child, nil
+}
+
+// CTCOrder moves the stop-loss of every filled child that is in profit to its
+// cost. Children that are not filled or not yet in profit are left alone; it
+// is an error only if no child could be moved.
+func (s *OMSService) CTCOrder(parentID string, ltp float64) (*models.ScalperOrder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parentOrder, err := s.repo.GetScalperOrder(parentID)
+	if err != nil {
+		return nil, err
+	}
+	if ltp <= 0 {
+		ltp, _ = s.prices.LastPrice(parentOrder.Symbol)
+	}
+	moved := 0
+	var lastErr error
+	for i := range parentOrder.ChildOrders {
+		child := &parentOrder.ChildOrders[i]
+		if !child.IsEntry() || child.FilledQuantity == 0 {
+			continue
+		}
+		if err := s.coverCost(child, ltp); err != nil {
+			lastErr = err
+			continue
+		}
+		moveStopLegs(parentOrder, child)
+		moved++
+	}
+	if moved == 0 {
+		if lastErr == nil {
+			lastErr = conflictf("no filled child orders to CTC")
+		}
+		return nil, lastErr
+	}
+	if err := s.commit(models.Event{Type: models.EventStopLossMoved, OrderID: parentID, ScalperOrder: parentOrder}); err != nil {
+		return nil, err
+	}
+	return parentOrder, nil
+}
+
+// costPrice is the price at which closing order's filled quantity recovers
+// its entry plus trading costs.
+func (s *OMSService) costPrice(order *models.Order) float64 {
+	cost := s.ctcCosts.PerUnit + order.AvgFillPrice*s.ctcCosts.Bps/10000
+	if order.Side == "sell" {
+		return order.AvgFillPrice - cost
+	}
+	return order.AvgFillPrice + cost
+}
+
+// coverCost moves order's stop-loss to its cost price if ltp is beyond it.
+// A stop that is already at or past the cost price is left where it is.
+func (s *OMSService) coverCost(order *models.Order, ltp float64) error {
+	if order.FilledQuantity == 0 {
+		return conflictf("order %s has no filled quantity", order.ID)
+	}
+	if ltp <= 0 {
+		return conflictf("no last traded price for %s", order.Symbol)
+	}
+
+	cost := s.costPrice(order)
+	if order.Side == "sell" {
+		if ltp >= cost {
+			return fmt.Errorf("%w: %s ltp %g, cost %g", ErrNotInProfit, order.ID, ltp, cost)
+		}
+		if order.StopLoss == 0 || order.StopLoss > cost {
+			order.StopLoss = cost
+			order.UpdatedAt = time.Now().Unix()
+		}
+		return nil
+	}
+	if ltp <= cost {
+		return fmt.Errorf("%w: %s ltp %g, cost %g", ErrNotInProfit, order.ID, ltp, cost)
+	}
+	if order.StopLoss < cost {
+		order.StopLoss = cost
+		order.UpdatedAt = time.Now().Unix()
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+)
+
+// ErrorCode is the machine-readable kind of an error returned by the OMS.
+type ErrorCode string
+
+const (
+	CodeNotFound          ErrorCode = "not_found"          // The order, trade or position does not exist
+	CodeValidationFailed  ErrorCode = "validation_failed"  // The request is malformed or inconsistent
+	CodeInvalidTransition ErrorCode = "invalid_transition" // The order is in a status that does not allow it
+	CodeConflict          ErrorCode = "conflict"           // The request conflicts with the current state
+	CodeRiskRejected      ErrorCode = "risk_rejected"      // A pre-trade risk check rejected the order
+	CodeAccessDenied      ErrorCode = "access_denied"      // It belongs to another account
+	CodeInternal          ErrorCode = "internal"           // The OMS failed; the request may be retried
+)
+
+// Every error the OMS returns for a bad request is, or wraps, one of these
+// or a *RiskError, so errors.Is tells its kind.
+var (
+	// ErrNotFound is matched by every lookup that matches nothing.
+	ErrNotFound = repository.ErrNotFound
+	// ErrValidation is matched by requests that are malformed or
+	// inconsistent in themselves.
+	ErrValidation = errors.New("validation failed")
+	// ErrConflict is matched by requests the current state of the OMS
+	// refuses, such as a change against an outdated version.
+	ErrConflict = errors.New("conflict")
+)
+
+// notFoundf returns an ErrNotFound error naming what is missing.
+func notFoundf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrNotFound, msg: fmt.Sprintf(format, args...)}
+}
+
+// invalidf returns an ErrValidation error explaining what is wrong.
+func invalidf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrValidation, msg: fmt.Sprintf(format, args...)}
+}
+
+// conflictf returns an ErrConflict error explaining what is in the way.
+func conflictf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrConflict, msg: fmt.Sprintf(format, args...)}
+}
+
+// kindError is an error of one kind that reads as its message alone.
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Unwrap() error {
+	return e.kind
+}
+
+// Code returns the kind of err. Errors of no known kind are CodeInternal.
+func Code(err error) ErrorCode {
+	var riskErr *RiskError
+	switch {
+	case errors.As(err, &riskErr):
+		return CodeRiskRejected
+	case errors.Is(err, ErrAccessDenied):
+		return CodeAccessDenied
+	case errors.Is(err, ErrNotFound):
+		return CodeNotFound
+	case errors.Is(err, ErrInvalidTransition):
+		return CodeInvalidTransition
+	case errors.Is(err, ErrValidation):
+		return CodeValidationFailed
+	case errors.Is(err, ErrConflict):
+		return CodeConflict
+	}
+	return CodeInternal
+}
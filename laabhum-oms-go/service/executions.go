@@ -0,0 +1,126 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// ErrUnknownBrokerOrder is returned for an execution report that matches no
+// OMS order.
+var ErrUnknownBrokerOrder = notFoundf("no order for broker order")
+
+// brokerActor is the actor of changes made by execution reports.
+const brokerActor = "broker"
+
+// ApplyExecutionReport brings the order report is about in line with the
+// broker: acceptance opens a pending order, a fill books the remaining
+// quantity and a cancellation or rejection ends the order. An IOC order the
+// broker accepts without filling it is canceled at once. It returns the order
+// and whether the report changed it.
+//
+// Reports are matched on the broker order id, or on the OMS order id the first
+// time the broker echoes it, which links the two. Duplicates and reports that
+// arrive after a newer one, or after the order has ended, change nothing.
+func (s *OMSService) ApplyExecutionReport(report models.ExecutionReport) (*models.Order, bool, error) {
+	if report.BrokerOrderID == "" {
+		return nil, false, invalidf("broker order id is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, err := s.brokerOrder(report)
+	if err != nil {
+		return nil, false, err
+	}
+	if order.Status.IsTerminal() || (report.Timestamp != 0 && report.Timestamp <= order.BrokerUpdated) {
+		return order, false, nil
+	}
+	linked := order.BrokerOrderID != report.BrokerOrderID
+	order.BrokerOrderID = report.BrokerOrderID
+	if report.Timestamp != 0 {
+		order.BrokerUpdated = report.Timestamp
+	}
+
+	ev := models.Event{Type: models.EventOrderAccepted, Actor: brokerActor, Order: order}
+	switch report.Status {
+	case models.ExecutionAccepted:
+		if order.Status == models.OrderStatusPending {
+			if err := transition(order, models.OrderStatusOpen, "accepted by broker"); err != nil {
+				return nil, false, err
+			}
+			if order.TimeInForce == models.TimeInForceIOC {
+				if err := transition(order, models.OrderStatusCanceled, "IOC unfilled on acceptance"); err != nil {
+					return nil, false, err
+				}
+				ev.Type = models.EventOrderCanceled
+			}
+		} else if !linked && report.Timestamp == 0 {
+			return order, false, nil
+		}
+	case models.ExecutionFilled:
+		price := report.Price
+		if price == 0 {
+			price = order.Price
+		}
+		if price == 0 {
+			return nil, false, invalidf("execution report for order %s has no fill price", order.ID)
+		}
+		trade, err := applyFill(order, models.Fill{
+			OrderID:     order.ID,
+			ExecutionID: report.BrokerOrderID + "/filled",
+			Quantity:    order.RemainingQuantity(),
+			Price:       price,
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		ev = models.Event{Type: models.EventOrderFilled, Actor: brokerActor, Order: order, Trades: []models.Trade{*trade}}
+	case models.ExecutionCanceled:
+		if err := transition(order, models.OrderStatusCanceled, "canceled by broker"); err != nil {
+			return nil, false, err
+		}
+		ev.Type = models.EventOrderCanceled
+	case models.ExecutionRejected:
+		// A broker can reject an order it never accepted; one that was
+		// working is treated as canceled.
+		to := models.OrderStatusRejected
+		if !canTransition(order.Status, to) {
+			to = models.OrderStatusCanceled
+		}
+		if err := transition(order, to, "rejected by broker"); err != nil {
+			return nil, false, err
+		}
+		ev.Type = models.EventOrderCanceled
+		if to == models.OrderStatusRejected {
+			ev.Type = models.EventOrderRejected
+		}
+	default:
+		return nil, false, invalidf("unknown execution status %q", report.Status)
+	}
+
+	if err := s.commitOrder(ev); err != nil {
+		return nil, false, err
+	}
+	return order, true, nil
+}
+
+// brokerOrder finds the order an execution report is about. Callers must hold
+// s.mu.
+func (s *OMSService) brokerOrder(report models.ExecutionReport) (*models.Order, error) {
+	if report.OrderID != "" {
+		if order, err := s.repo.GetOrder(report.OrderID); err == nil {
+			return order, nil
+		}
+	}
+	orders, err := s.repo.GetOrders()
+	if err != nil {
+		return nil, err
+	}
+	for i := range orders {
+		if orders[i].BrokerOrderID == report.BrokerOrderID {
+			return &orders[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w %s", ErrUnknownBrokerOrder, report.BrokerOrderID)
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/google/uuid"
+)
+
+// ExitAllTrades flattens every open position of accountID, or in the whole
+// OMS when accountID is empty: scalper children and standalone orders alike.
+// It returns the market exit orders it generated.
+func (s *OMSService) ExitAllTrades(accountID string) ([]models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.exitAll(inAccount(accountID))
+}
+
+// exitAll flattens the scalper orders whose parent order matches and the
+// standalone entries that match.
+func (s *OMSService) exitAll(match func(models.Order) bool) ([]models.Order, error) {
+	parents, err := s.repo.GetScalperOrders()
+	if err != nil {
+		return nil, err
+	}
+	var exits []models.Order
+	for i := range parents {
+		if !match(parents[i].ParentOrder) {
+			continue
+		}
+		generated, err := s.exitScalperOrder(&parents[i], "")
+		if err != nil {
+			return exits, err
+		}
+		exits = append(exits, generated...)
+	}
+
+	generated, err := s.exitStandaloneOrders(match)
+	if err != nil {
+		return exits, err
+	}
+	return append(exits, generated...), nil
+}
+
+// ExitScalperTrades flattens every child of a scalper order.
+func (s *OMSService) ExitScalperTrades(parentID string) ([]models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parentOrder, err := s.repo.GetScalperOrder(parentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.exitScalperOrder(parentOrder, "")
+}
+
+// ExitChildTrade flattens one child of a scalper order.
+func (s *OMSService) ExitChildTrade(parentID, childID string) ([]models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parentOrder, err := s.repo.GetScalperOrder(parentID)
+	if err != nil {
+		return nil, err
+	}
+	child := findChild(parentOrder, childID)
+	if child == nil || !child.IsEntry() {
+		return nil, repository.ErrOrderNotFound
+	}
+	return s.exitScalperOrder(parentOrder, childID)
+}
+
+// exitScalperOrder flattens the entry children of parent, or only childID
+// when it is set, and commits the result as one event.
+func (s *OMSService) exitScalperOrder(parent *models.ScalperOrder, childID string) ([]models.Order, error) {
+	before := transitionCounts(parent.ChildOrders)
+	var exits []models.Order
+	for i := 0; i < len(before); i++ {
+		entry := parent.ChildOrders[i]
+		if !entry.IsEntry() || (childID != "" && entry.ID != childID) {
+			continue
+		}
+		exit, err := flatten(&parent.ChildOrders, i)
+		if err != nil {
+			return nil, err
+		}
+		if exit != nil {
+			exits = append(exits, *exit)
+		}
+	}
+	if len(exits) == 0 && !changedSince(parent.ChildOrders, before) {
+		return nil, nil
+	}
+
+	if err := settle(parent); err != nil {
+		return nil, err
+	}
+	orderID := parent.ID
+	if childID != "" {
+		orderID = childID
+	}
+	if err := s.commit(models.Event{Type: models.EventTradeExited, OrderID: orderID, ScalperOrder: parent}); err != nil {
+		return nil, err
+	}
+	return exits, nil
+}
+
+// exitStandaloneOrders flattens every matching entry that is not part of a
+// scalper order. Each changed order is committed as its own event. The
+// orders are listed once and the closing orders indexed by the entry they
+// close, so an exit is linear in the number of orders.
+func (s *OMSService) exitStandaloneOrders(match func(models.Order) bool) ([]models.Order, error) {
+	orders, err := s.repo.GetOrders()
+	if err != nil {
+		return nil, err
+	}
+	closing := make(map[string][]models.Order)
+	for _, order := range orders {
+		if order.ParentID == "" && order.LinkedOrderID != "" {
+			closing[order.LinkedOrderID] = append(closing[order.LinkedOrderID], order)
+		}
+	}
+	var exits []models.Order
+	for _, entry := range orders {
+		if entry.ParentID != "" || !entry.IsEntry() || !match(entry) {
+			continue
+		}
+		// Nothing filled and nothing working: there is nothing to flatten.
+		if entry.Status.IsTerminal() && entry.FilledQuantity == 0 {
+			continue
+		}
+		group := append([]models.Order{entry}, closing[entry.ID]...)
+
+		before := transitionCounts(group)
+		exit, err := flatten(&group, 0)
+		if err != nil {
+			return exits, err
+		}
+		for i := range before {
+			if len(group[i].Transitions) == before[i] {
+				continue
+			}
+			if err := s.commit(models.Event{Type: models.EventOrderCanceled, OrderID: group[i].ID, Order: &group[i]}); err != nil {
+				return exits, err
+			}
+		}
+		if exit != nil {
+			if err := s.commit(models.Event{Type: models.EventTradeExited, OrderID: exit.ID, Order: exit}); err != nil {
+				return exits, err
+			}
+			exits = append(exits, *exit)
+		}
+	}
+	return exits, nil
+}
+
+// flatten closes the position opened by (*orders)[i]. It cancels the unfilled
+// part of the entry and every resting exit, target and stop-loss linked to it,
+// then appends a market exit for whatever quantity is still open. The exit is
+// also returned; it is nil when nothing was open.
+func flatten(orders *[]models.Order, i int) (*models.Order, error) {
+	entry := &(*orders)[i]
+	if !entry.Status.IsTerminal() {
+		if err := transition(entry, models.OrderStatusCanceled, "canceled to exit trade"); err != nil {
+			return nil, err
+		}
+	}
+
+	open := entry.FilledQuantity
+	for j := range *orders {
+		closing := &(*orders)[j]
+		if closing.LinkedOrderID != entry.ID || closing.IsEntry() {
+			continue
+		}
+		if !closing.Status.IsTerminal() {
+			if err := transition(closing, models.OrderStatusCanceled, "replaced by exit trade"); err != nil {
+				return nil, err
+			}
+		}
+		open -= closing.FilledQuantity
+	}
+	if open <= 0 {
+		return nil, nil
+	}
+
+	exit := models.Order{
+		ID:            uuid.NewString(),
+		ParentID:      entry.ParentID,
+		Leg:           entry.Leg,
+		AccountID:     entry.AccountID,
+		UserID:        entry.UserID,
+		Symbol:        entry.Symbol,
+		Quantity:      open,
+		OrderType:     models.OrderTypeMarket,
+		TimeInForce:   models.TimeInForceDay,
+		Side:          oppositeSide(entry.Side),
+		Product:       entry.Product,
+		Role:          models.OrderRoleExit,
+		LinkedOrderID: entry.ID,
+		Status:        models.OrderStatusNew,
+		CreatedAt:     time.Now().Unix(),
+		Description:   fmt.Sprintf("market exit of %s", entry.ID),
+	}
+	if err := transition(&exit, models.OrderStatusPending, "generated to exit trade"); err != nil {
+		return nil, err
+	}
+	*orders = append(*orders, exit)
+	return &exit, nil
+}
+
+func oppositeSide(side string) string {
+	if side == "sell" {
+		return "buy"
+	}
+	return "sell"
+}
+
+// transitionCounts records how many transitions each order has, so callers
+// can tell afterwards which orders changed.
+func transitionCounts(orders []models.Order) []int {
+	counts := make([]int, len(orders))
+	for i, order := range orders {
+		counts[i] = len(order.Transitions)
+	}
+	return counts
+}
+
+func changedSince(orders []models.Order, counts []int) bool {
+	for i, count := range counts {
+		if len(orders[i].Transitions) != count {
+			return true
+		}
+	}
+	return len(orders) != len(counts)
+}
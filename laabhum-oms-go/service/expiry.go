@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// TradingSession says when the trading day closes. DAY orders still working
+// at the close expire.
+type TradingSession struct {
+	CloseHour   int
+	CloseMinute int
+	Location    *time.Location
+}
+
+// DefaultTradingSession closes at 15:30 local time.
+var DefaultTradingSession = TradingSession{CloseHour: 15, CloseMinute: 30, Location: time.Local}
+
+// WithTradingSession sets the session used to expire DAY orders.
+func WithTradingSession(session TradingSession) Option {
+	return func(s *OMSService) {
+		s.session = session
+	}
+}
+
+// closeAfter returns the first session close strictly after the given time.
+func (t TradingSession) closeAfter(unix int64) time.Time {
+	location := t.Location
+	if location == nil {
+		location = time.Local
+	}
+	at := time.Unix(unix, 0).In(location)
+	close := time.Date(at.Year(), at.Month(), at.Day(), t.CloseHour, t.CloseMinute, 0, 0, location)
+	if !at.Before(close) {
+		close = close.AddDate(0, 0, 1)
+	}
+	return close
+}
+
+// expiresAt returns when order stops being valid, or false if it never does.
+func (s *OMSService) expiresAt(order models.Order) (time.Time, bool) {
+	switch order.TimeInForce {
+	case models.TimeInForceGTD:
+		return time.Unix(order.ExpiresAt, 0), true
+	case models.TimeInForceGTC:
+		return time.Time{}, false
+	case models.TimeInForceIOC:
+		// Canceled by their first execution report instead.
+		return time.Time{}, false
+	default:
+		// DAY and orders created before time in force existed.
+		return s.session.closeAfter(order.CreatedAt), true
+	}
+}
+
+// ExpireOrders moves every working order whose validity ended at or before
+// now to expired, and returns the orders it expired.
+func (s *OMSService) ExpireOrders(now time.Time) ([]models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders, err := s.repo.GetWorkingOrders()
+	if err != nil {
+		return nil, err
+	}
+	var expired []models.Order
+	for _, listed := range orders {
+		if _, ok := s.expiresAt(listed); !ok {
+			continue
+		}
+		// Expiring an entry can cancel its bracket legs, so work from the
+		// stored state rather than the listing.
+		order, err := s.repo.GetOrder(listed.ID)
+		if err != nil {
+			return expired, err
+		}
+		// Inactive bracket legs (status new) follow their entry instead.
+		if order.Status.IsTerminal() || order.Status == models.OrderStatusNew {
+			continue
+		}
+		at, ok := s.expiresAt(*order)
+		if !ok || now.Before(at) {
+			continue
+		}
+		reason := fmt.Sprintf("%s validity ended at %s", order.TimeInForce, at.Format(time.RFC3339))
+		if err := transition(order, models.OrderStatusExpired, reason); err != nil {
+			return expired, err
+		}
+		if err := s.commitOrder(models.Event{Type: models.EventOrderExpired, Order: order}); err != nil {
+			return expired, err
+		}
+		expired = append(expired, *order)
+	}
+	return expired, nil
+}
+
+// RunExpiry calls ExpireOrders every interval until ctx is done. It also
+// prunes the client order ids that have outlived the idempotency window.
+func (s *OMSService) RunExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			expired, err := s.ExpireOrders(now)
+			if err != nil {
+				log.Printf("ERROR: expiring orders: %v", err)
+			}
+			if len(expired) > 0 {
+				log.Printf("INFO: expired %d orders", len(expired))
+			}
+			if err := s.PruneIdempotencyRecords(now); err != nil {
+				log.Printf("ERROR: pruning client order ids: %v", err)
+			}
+		}
+	}
+}
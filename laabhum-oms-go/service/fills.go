@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/google/uuid"
+)
+
+// RecordFill books an execution against an order or scalper child order. It
+// updates the filled quantity, the volume-weighted average fill price and the
+// order status. Fills are idempotent on ExecutionID: a fill that was already
+// recorded returns the original trade and recorded is false.
+func (s *OMSService) RecordFill(fill models.Fill) (trade *models.Trade, recorded bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.recordFill(fill)
+}
+
+func (s *OMSService) recordFill(fill models.Fill) (*models.Trade, bool, error) {
+	if fill.ExecutionID == "" {
+		return nil, false, invalidf("execution id is required")
+	}
+	if fill.Quantity <= 0 {
+		return nil, false, invalidf("fill quantity must be positive")
+	}
+	if fill.Price <= 0 {
+		return nil, false, invalidf("fill price must be positive")
+	}
+
+	existing, err := s.repo.GetTradeByExecutionID(fill.ExecutionID)
+	switch {
+	case err == nil:
+		if existing.OrderID != fill.OrderID {
+			return nil, false, conflictf("execution %s was already booked against order %s", fill.ExecutionID, existing.OrderID)
+		}
+		return existing, false, nil
+	case !errors.Is(err, repository.ErrTradeNotFound):
+		return nil, false, err
+	}
+
+	order, err := s.repo.GetOrder(fill.OrderID)
+	if err != nil {
+		return nil, false, err
+	}
+	trade, err := applyFill(order, fill)
+	if err != nil {
+		return nil, false, err
+	}
+	if order.TimeInForce == models.TimeInForceIOC && order.Status == models.OrderStatusPartiallyFilled {
+		if err := transition(order, models.OrderStatusCanceled, "IOC remainder canceled"); err != nil {
+			return nil, false, err
+		}
+	}
+	ev := models.Event{Type: models.EventOrderFilled, Actor: fill.Actor, Order: order, Trades: []models.Trade{*trade}}
+	if err := s.commitOrder(ev); err != nil {
+		return nil, false, err
+	}
+	return &ev.Trades[0], true, nil
+}
+
+// applyFill adds fill to order and returns the resulting trade. The order is
+// only modified if the fill is valid for it.
+func applyFill(order *models.Order, fill models.Fill) (*models.Trade, error) {
+	if fill.Quantity > order.RemainingQuantity() {
+		return nil, invalidf("fill of %d exceeds remaining quantity %d of order %s", fill.Quantity, order.RemainingQuantity(), order.ID)
+	}
+
+	next := models.OrderStatusPartiallyFilled
+	if fill.Quantity == order.RemainingQuantity() {
+		next = models.OrderStatusFilled
+	}
+	reason := fmt.Sprintf("filled %d @ %g (execution %s)", fill.Quantity, fill.Price, fill.ExecutionID)
+	if err := transition(order, next, reason); err != nil {
+		return nil, err
+	}
+
+	filledValue := order.AvgFillPrice*float64(order.FilledQuantity) + fill.Price*float64(fill.Quantity)
+	order.FilledQuantity += fill.Quantity
+	order.AvgFillPrice = filledValue / float64(order.FilledQuantity)
+
+	timestamp := fill.Timestamp
+	if timestamp == 0 {
+		timestamp = time.Now().Unix()
+	}
+	return &models.Trade{
+		ID:          uuid.NewString(),
+		OrderID:     order.ID,
+		AccountID:   order.AccountID,
+		UserID:      order.UserID,
+		ParentID:    order.ParentID,
+		ExecutionID: fill.ExecutionID,
+		Symbol:      order.Symbol,
+		Side:        order.Side,
+		Product:     order.Product,
+		Quantity:    fill.Quantity,
+		Price:       fill.Price,
+		Timestamp:   timestamp,
+	}, nil
+}
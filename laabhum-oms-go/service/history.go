@@ -0,0 +1,87 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// OrderHistory returns the status transitions, amendments and fills of order
+// id, oldest first. Changes made in the same second are ordered by the order
+// version they produced; within one change, a fill comes before the status
+// transition it causes.
+func (s *OMSService) OrderHistory(id string) (*models.OrderHistory, error) {
+	order, err := s.repo.GetOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := s.repo.GetTrades(id)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &models.OrderHistory{OrderID: id, Entries: []models.HistoryEntry{}}
+	var versions []int
+	add := func(entry models.HistoryEntry, version int) {
+		history.Entries = append(history.Entries, entry)
+		versions = append(versions, version)
+	}
+	for i := range order.Amendments {
+		amendment := &order.Amendments[i]
+		add(models.HistoryEntry{
+			Kind:      models.HistoryAmendment,
+			Timestamp: amendment.Timestamp,
+			Event:     models.EventOrderModified,
+			Actor:     amendment.Actor,
+			Amendment: amendment,
+		}, amendment.Version)
+	}
+	for i := range trades {
+		// GetTrades of a scalper order also returns the trades of its
+		// children; id names a single order here.
+		if trades[i].OrderID != id {
+			continue
+		}
+		add(models.HistoryEntry{
+			Kind:      models.HistoryFill,
+			Timestamp: trades[i].Timestamp,
+			Actor:     trades[i].Actor,
+			Trade:     &trades[i],
+		}, trades[i].OrderVersion)
+	}
+	for i := range order.Transitions {
+		transition := &order.Transitions[i]
+		add(models.HistoryEntry{
+			Kind:       models.HistoryStatus,
+			Timestamp:  transition.Timestamp,
+			Event:      transition.Event,
+			Actor:      transition.Actor,
+			Transition: transition,
+		}, transition.Version)
+	}
+	sort.Stable(historyByTime{entries: history.Entries, versions: versions})
+	return history, nil
+}
+
+// historyByTime sorts history entries by time, then by the order version they
+// produced. Entries from before versions were recorded have version 0.
+type historyByTime struct {
+	entries  []models.HistoryEntry
+	versions []int
+}
+
+func (h historyByTime) Len() int {
+	return len(h.entries)
+}
+
+func (h historyByTime) Less(i, j int) bool {
+	if h.entries[i].Timestamp != h.entries[j].Timestamp {
+		return h.entries[i].Timestamp < h.entries[j].Timestamp
+	}
+	return h.versions[i] < h.versions[j]
+}
+
+func (h historyByTime) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.versions[i], h.versions[j] = h.versions[j], h.versions[i]
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+)
+
+// DefaultIdempotencyWindow is how long a client order id is remembered.
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// ErrIdempotencyMismatch is returned when a client order id that is still
+// remembered is submitted again with a different order.
+var ErrIdempotencyMismatch = conflictf("client order id was already used for a different order")
+
+// WithIdempotencyWindow sets how long a client order id is remembered. Within
+// the window, resubmitting an order under the same id returns the original;
+// after it, the id may be used for a new order.
+func WithIdempotencyWindow(window time.Duration) Option {
+	return func(s *OMSService) {
+		s.idempotencyWindow = window
+	}
+}
+
+// idempotencyKey scopes a client order id to the account placing the order.
+func idempotencyKey(order models.Order) string {
+	return order.AccountID + "/" + order.ClientOrderID
+}
+
+// requestHash fingerprints an order as it was submitted.
+func requestHash(order models.Order) (string, error) {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replayOrder returns the order created by an earlier submission under key,
+// or nil if key is unused or no longer remembered. Callers must hold s.mu.
+func (s *OMSService) replayOrder(key, hash string, now time.Time) (*models.Order, error) {
+	rec, err := s.repo.GetIdempotencyRecord(key)
+	if errors.Is(err, repository.ErrIdempotencyRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if now.Sub(time.Unix(rec.CreatedAt, 0)) >= s.idempotencyWindow {
+		return nil, nil
+	}
+	if rec.RequestHash != hash {
+		return nil, fmt.Errorf("%w: %s", ErrIdempotencyMismatch, key)
+	}
+	return s.repo.GetOrder(rec.OrderID)
+}
+
+// PruneIdempotencyRecords forgets the client order ids that have outlived the
+// idempotency window.
+func (s *OMSService) PruneIdempotencyRecords(now time.Time) error {
+	return s.repo.DeleteIdempotencyRecords(now.Add(-s.idempotencyWindow).Unix())
+}
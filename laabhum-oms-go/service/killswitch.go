@@ -0,0 +1,196 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+)
+
+// ErrKillSwitchNotEngaged is returned when releasing a kill switch that is
+// not engaged.
+var ErrKillSwitchNotEngaged = conflictf("kill switch is not engaged")
+
+// KillSwitchRequest engages or releases the kill switch of AccountID, or the
+// OMS-wide one when AccountID is empty.
+type KillSwitchRequest struct {
+	AccountID string `json:"account_id,omitempty"`
+	Actor     string `json:"actor,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	// Flatten also exits the open positions when engaging.
+	Flatten bool `json:"flatten,omitempty"`
+}
+
+// EngageKillSwitch blocks new orders in the requested scope, cancels every
+// resting order in it and, if asked, flattens its open positions with market
+// exits. The switch stays engaged until ReleaseKillSwitch. Engaging an engaged
+// switch sweeps the scope again.
+func (s *OMSService) EngageKillSwitch(req KillSwitchRequest) (*models.KillSwitch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ks, err := s.killSwitch(req.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	// s.mu keeps new orders out until the switch is committed below, so
+	// sweeping first is safe. A failed sweep still engages the switch.
+	canceled, exits, sweepErr := s.sweep(req.AccountID, req.Actor, req.Flatten)
+
+	now := time.Now().Unix()
+	ks.Engaged = true
+	ks.Reason = req.Reason
+	ks.UpdatedAt = now
+	ks.Audit = append(ks.Audit, models.KillSwitchAction{
+		Action:    models.KillSwitchEngage,
+		Actor:     req.Actor,
+		Reason:    req.Reason,
+		Flatten:   req.Flatten,
+		Canceled:  canceled,
+		Exits:     exits,
+		Timestamp: now,
+	})
+	if err := s.commit(models.Event{Type: models.EventKillSwitchEngaged, OrderID: ks.AccountID, Actor: req.Actor, KillSwitch: ks}); err != nil {
+		return nil, err
+	}
+	log.Printf("WARN: kill switch %s engaged by %q: %s (%d orders canceled, %d exits)", scopeName(ks.AccountID), req.Actor, req.Reason, canceled, exits)
+	if sweepErr != nil {
+		return ks, fmt.Errorf("kill switch engaged, but sweeping orders failed: %w", sweepErr)
+	}
+	return ks, nil
+}
+
+// ReleaseKillSwitch lets orders in the requested scope through again.
+func (s *OMSService) ReleaseKillSwitch(req KillSwitchRequest) (*models.KillSwitch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ks, err := s.killSwitch(req.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !ks.Engaged {
+		return nil, fmt.Errorf("%w for %s", ErrKillSwitchNotEngaged, scopeName(req.AccountID))
+	}
+	now := time.Now().Unix()
+	ks.Engaged = false
+	ks.UpdatedAt = now
+	ks.Audit = append(ks.Audit, models.KillSwitchAction{
+		Action:    models.KillSwitchRelease,
+		Actor:     req.Actor,
+		Reason:    req.Reason,
+		Timestamp: now,
+	})
+	if err := s.commit(models.Event{Type: models.EventKillSwitchReleased, OrderID: ks.AccountID, Actor: req.Actor, KillSwitch: ks}); err != nil {
+		return nil, err
+	}
+	log.Printf("WARN: kill switch %s released by %q: %s", scopeName(ks.AccountID), req.Actor, req.Reason)
+	return ks, nil
+}
+
+// GetKillSwitches returns every kill switch with its audit trail.
+func (s *OMSService) GetKillSwitches() ([]models.KillSwitch, error) {
+	return s.repo.GetKillSwitches()
+}
+
+// killSwitch returns the stored kill switch of accountID, or a fresh released
+// one if it never had one.
+func (s *OMSService) killSwitch(accountID string) (*models.KillSwitch, error) {
+	ks, err := s.repo.GetKillSwitch(accountID)
+	if errors.Is(err, repository.ErrKillSwitchNotFound) {
+		return &models.KillSwitch{AccountID: accountID}, nil
+	}
+	return ks, err
+}
+
+// checkKillSwitch rejects orders while the OMS-wide or the account's kill
+// switch is engaged.
+func (s *OMSService) checkKillSwitch(accountID string) error {
+	scopes := []string{""}
+	if accountID != "" {
+		scopes = append(scopes, accountID)
+	}
+	for _, scope := range scopes {
+		ks, err := s.killSwitch(scope)
+		if err != nil {
+			return err
+		}
+		if ks.Engaged {
+			return reject(RiskKillSwitch, "kill switch %s is engaged: %s", scopeName(scope), ks.Reason)
+		}
+	}
+	return nil
+}
+
+// sweep cancels every resting order in the scope of accountID on behalf of
+// actor and, with flatten, exits what is still open. It returns how many
+// orders it canceled and how many exits it generated.
+func (s *OMSService) sweep(accountID, actor string, flatten bool) (canceled, exits int, err error) {
+	inScope := inAccount(accountID)
+	const reason = "canceled by kill switch"
+
+	parents, err := s.repo.GetScalperOrders()
+	if err != nil {
+		return 0, 0, err
+	}
+	for i := range parents {
+		parent := &parents[i]
+		if !inScope(parent.ParentOrder) {
+			continue
+		}
+		before := canceled
+		for j := range parent.ChildOrders {
+			child := &parent.ChildOrders[j]
+			if child.Status.IsTerminal() {
+				continue
+			}
+			if err := transition(child, models.OrderStatusCanceled, reason); err != nil {
+				return canceled, 0, err
+			}
+			canceled++
+		}
+		if canceled == before {
+			continue
+		}
+		if err := settle(parent); err != nil {
+			return canceled, 0, err
+		}
+		if err := s.commit(models.Event{Type: models.EventOrderCanceled, OrderID: parent.ID, Actor: actor, ScalperOrder: parent}); err != nil {
+			return canceled, 0, err
+		}
+	}
+
+	orders, err := s.repo.GetOrders()
+	if err != nil {
+		return canceled, 0, err
+	}
+	for i := range orders {
+		order := &orders[i]
+		if order.ParentID != "" || order.Status.IsTerminal() || !inScope(*order) {
+			continue
+		}
+		if err := transition(order, models.OrderStatusCanceled, reason); err != nil {
+			return canceled, 0, err
+		}
+		if err := s.commit(models.Event{Type: models.EventOrderCanceled, OrderID: order.ID, Actor: actor, Order: order}); err != nil {
+			return canceled, 0, err
+		}
+		canceled++
+	}
+
+	if !flatten {
+		return canceled, 0, nil
+	}
+	generated, err := s.exitAll(inScope)
+	return canceled, len(generated), err
+}
+
+func scopeName(accountID string) string {
+	if accountID == "" {
+		return "for the OMS"
+	}
+	return "for account " + accountID
+}
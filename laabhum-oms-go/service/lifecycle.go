@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// ErrInvalidTransition is returned when an order is asked to move to a status
+// that is not reachable from its current one.
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+// orderTransitions lists, for every non-terminal status, the statuses an order
+// may move to next. Terminal statuses have no entry.
+var orderTransitions = map[models.OrderStatus][]models.OrderStatus{
+	models.OrderStatusNew: {
+		models.OrderStatusPending,
+		models.OrderStatusRejected,
+		models.OrderStatusCanceled,
+	},
+	models.OrderStatusPending: {
+		models.OrderStatusOpen,
+		models.OrderStatusPartiallyFilled,
+		models.OrderStatusFilled,
+		models.OrderStatusRejected,
+		models.OrderStatusCanceled,
+		models.OrderStatusExpired,
+	},
+	models.OrderStatusOpen: {
+		models.OrderStatusPartiallyFilled,
+		models.OrderStatusFilled,
+		models.OrderStatusCanceled,
+		models.OrderStatusExpired,
+	},
+	models.OrderStatusPartiallyFilled: {
+		models.OrderStatusPartiallyFilled,
+		models.OrderStatusFilled,
+		models.OrderStatusCanceled,
+		models.OrderStatusExpired,
+	},
+}
+
+// canTransition reports whether an order in status from may move to status to.
+func canTransition(from, to models.OrderStatus) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transition moves the order to the given status and records the change.
+// Illegal moves leave the order untouched and return ErrInvalidTransition.
+func transition(order *models.Order, to models.OrderStatus, reason string) error {
+	from := order.Status
+	if from == "" {
+		from = models.OrderStatusNew
+	}
+	if !canTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+
+	now := time.Now().Unix()
+	order.Status = to
+	order.UpdatedAt = now
+	order.Transitions = append(order.Transitions, models.StatusTransition{
+		From:      from,
+		To:        to,
+		Reason:    reason,
+		Timestamp: now,
+	})
+	return nil
+}
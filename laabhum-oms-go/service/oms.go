@@ -2,6 +2,8 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Mukilan-T/laabhum-oms-go/models"
@@ -9,122 +11,263 @@ import (
 	"github.com/google/uuid"
 )
 
+// Journal durably records events before they are applied to the repository.
+type Journal interface {
+	Append(ev *models.Event) error
+}
+
 type OMSService struct {
-	repo repository.OrderRepository
+	repo       repository.OrderRepository
+	journal    Journal
+	broker     BrokerAdapter
+	slicing    SlicingRule
+	session    TradingSession
+	ctcCosts   CTCCosts
+	riskChecks []RiskCheck
+	publishers []EventPublisher
+	prices     *PriceBook
+	positions  *PositionBook
+
+	// stops holds the IDs of the armed stops and guarded entries, keyed by
+	// symbol, so a price update only looks at the orders it can trigger. It
+	// is loaded on first use and then kept up to date by commit.
+	stops map[string]map[string]bool
+
+	// idempotencyWindow is how long client order ids are remembered.
+	idempotencyWindow time.Duration
+
+	// relayMu keeps outbox relays from overlapping; relayWake nudges a
+	// running relay after a commit queues events.
+	relayMu   sync.Mutex
+	relayWake chan struct{}
+
+	// mu serialises read-modify-write sequences against the repository so
+	// that two requests touching the same order cannot interleave.
+	mu sync.Mutex
 }
 
-func NewOMSService(repo repository.OrderRepository) *OMSService {
-	return &OMSService{repo: repo}
+// Option configures optional OMSService dependencies.
+type Option func(*OMSService)
+
+// WithJournal makes the service write every mutation to j before applying it.
+func WithJournal(j Journal) Option {
+	return func(s *OMSService) {
+		s.journal = j
+	}
 }
 
-func (s *OMSService) CreateScalperOrder(order models.ScalperOrder) (*models.ScalperOrder, error) {
-	order.ID = uuid.NewString()
-	order.CreatedAt = time.Now().Unix()
-	return s.repo.CreateScalperOrder(order)
+func NewOMSService(repo repository.OrderRepository, opts ...Option) *OMSService {
+	s := &OMSService{
+		repo:      repo,
+		session:   DefaultTradingSession,
+		prices:    NewPriceBook(),
+		positions: NewPositionBook(),
+		relayWake: make(chan struct{}, 1),
+
+		idempotencyWindow: DefaultIdempotencyWindow,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *OMSService) ExecuteChildOrder(parentID, childID string) error {
-	return s.repo.ExecuteChildOrder(parentID, childID)
+// commit records ev in the journal, if one is configured, and then applies it
+// to the repository. Every order ev changes gets a new version first, and
+// trades in ev also update the price and position books. When publishers are
+// configured, the order lifecycle events ev produces are queued in the outbox
+// as part of the same event, for the relay to deliver. The index of armed
+// stops follows the orders in ev. Callers must hold s.mu.
+func (s *OMSService) commit(ev models.Event) error {
+	if ev.Timestamp == 0 {
+		ev.Timestamp = time.Now().Unix()
+	}
+	if err := s.stampVersions(ev); err != nil {
+		return err
+	}
+	if len(s.publishers) > 0 {
+		events, err := s.orderEvents(ev)
+		if err != nil {
+			return err
+		}
+		ev.OrderEvents = events
+	}
+	if s.journal != nil {
+		if err := s.journal.Append(&ev); err != nil {
+			return fmt.Errorf("journal %s event: %w", ev.Type, err)
+		}
+	}
+	if err := repository.ApplyEvent(s.repo, ev); err != nil {
+		return err
+	}
+	for _, trade := range ev.Trades {
+		s.prices.Update(trade.Symbol, trade.Price)
+		s.positions.Apply(trade)
+	}
+	if ev.Order != nil {
+		s.indexStop(*ev.Order)
+	}
+	if ev.ScalperOrder != nil {
+		for _, child := range ev.ScalperOrder.ChildOrders {
+			s.indexStop(child)
+		}
+	}
+	if len(ev.OrderEvents) > 0 {
+		s.wakeRelay()
+	}
+	return nil
 }
 
 func (s *OMSService) GetTrades(parentID string) ([]models.Trade, error) {
 	return s.repo.GetTrades(parentID)
 }
 
+// CreateOrder validates, risk checks and books a new order. An order with a
+// ClientOrderID is only created once: resubmitting it within the idempotency
+// window returns the original order. Risk rejections are not remembered, so a
+// rejected order may be resubmitted under the same id.
 func (s *OMSService) CreateOrder(order models.Order) (*models.Order, error) {
-	order.ID = uuid.NewString()
-	order.CreatedAt = time.Now().Unix()
-	return s.repo.CreateOrder(order)
-}
-
-func (s *OMSService) GetOrders() ([]models.Order, error) {
-	return s.repo.GetOrders()
-}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-// ProcessOrder handles business logic for processing the order
-func (s *OMSService) ProcessOrder(order map[string]interface{}) error {
-	// Add business logic for order processing here
-	if len(order) == 0 {
-		return errors.New("invalid order data")
+	now := time.Now()
+	var idempotency *models.IdempotencyRecord
+	if order.ClientOrderID != "" {
+		hash, err := requestHash(order)
+		if err != nil {
+			return nil, err
+		}
+		key := idempotencyKey(order)
+		if original, err := s.replayOrder(key, hash, now); original != nil || err != nil {
+			return original, err
+		}
+		idempotency = &models.IdempotencyRecord{Key: key, RequestHash: hash, CreatedAt: now.Unix()}
 	}
+	order.ID = uuid.NewString()
+	order.CreatedAt = now.Unix()
 
-	// Save the order to the repository (database)
-	
+	if err := validateOrder(&order, order.CreatedAt); err != nil {
+		return nil, err
+	}
+	// A trailing stop starts trailing from the last traded price, if known.
+	if ltp, ok := s.prices.LastPrice(order.Symbol); ok {
+		trail(&order, ltp)
+	}
 
-	return nil
+	// Every order starts its life as "new"; whatever the client sent is ignored.
+	order.Status = models.OrderStatusNew
+	order.Transitions = nil
+	if err := s.checkRisk(order, now); err != nil {
+		return nil, s.rejectOrder(order, err)
+	}
+	if err := transition(&order, models.OrderStatusPending, "accepted by OMS"); err != nil {
+		return nil, err
+	}
+	if idempotency != nil {
+		idempotency.OrderID = order.ID
+	}
+	if err := s.commit(models.Event{Type: models.EventOrderCreated, OrderID: order.ID, Order: &order, Idempotency: idempotency}); err != nil {
+		return nil, err
+	}
+	return &order, nil
 }
-func (s *OMSService) PlaceOrder(order *models.Order) error {
-
-    // Implement the logic to place an order
-
-    return nil
 
+// rejectOrder records order as rejected when err is a risk rejection, so
+// rejections can be audited, and returns err carrying the order's ID.
+func (s *OMSService) rejectOrder(order models.Order, err error) error {
+	var riskErr *RiskError
+	if !errors.As(err, &riskErr) {
+		return err
+	}
+	if terr := transition(&order, models.OrderStatusRejected, fmt.Sprintf("%s: %s", riskErr.Code, riskErr.Reason)); terr != nil {
+		return terr
+	}
+	if cerr := s.commit(models.Event{Type: models.EventOrderRejected, OrderID: order.ID, Order: &order}); cerr != nil {
+		return cerr
+	}
+	rejected := *riskErr
+	rejected.OrderID = order.ID
+	return &rejected
 }
-// func (r *InMemoryOrderRepository) GetOrder(id string) (*models.Order, error) {
-
-//     order, exists := r.orders[id]
-
-//     if !exists {
 
-//         return nil, fmt.Errorf("order not found")
-
-//     }
+func (s *OMSService) GetOrders() ([]models.Order, error) {
+	return s.repo.GetOrders()
+}
 
-//     return order, nil
+func (s *OMSService) GetOrder(id string) (*models.Order, error) {
+	return s.repo.GetOrder(id)
+}
 
-// }
+// CancelOrder cancels a working order on behalf of actor.
+func (s *OMSService) CancelOrder(parentID, orderID, actor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-func (s *OMSService) ModifyOrder(parentID, childID string, newData map[string]interface{}) error {
 	// Fetch the existing order
-	order, err := s.repo.GetOrder(parentID)
+	order, err := s.lookupOrder(parentID, orderID)
 	if err != nil {
 		return err
 	}
 
-	// Update the order with new data
-	for key, value := range newData {
-		switch key {
-		case "status":
-			order.Status = value.(string)
-		case "quantity":
-			order.Quantity = value.(int)
-		case "price":
-			order.Price = value.(float64)
-		// Add more fields as needed
-		default:
-			return errors.New("invalid field in newData")
-		}
+	// Only orders that are still working can be canceled
+	if err := transition(order, models.OrderStatusCanceled, "canceled by user"); err != nil {
+		return err
 	}
 
 	// Save the updated order back to the repository
-	err = s.repo.UpdateOrder(order)
+	err = s.commitOrder(models.Event{Type: models.EventOrderCanceled, Actor: actor, Order: order})
 	if err != nil {
-		return errors.New("failed to update order")
+		return fmt.Errorf("failed to update order: %w", err)
 	}
 
 	return nil
 }
 
-func (s *OMSService) CancelOrder(parentID, orderID string) error {
-	// Fetch the existing order
-	order, err := s.repo.GetOrder(parentID)
+// lookupOrder fetches orderID, checking that it belongs to parentID when it is
+// a scalper child. Standalone orders are addressed with parentID == orderID.
+func (s *OMSService) lookupOrder(parentID, orderID string) (*models.Order, error) {
+	if orderID == "" {
+		orderID = parentID
+	}
+	order, err := s.repo.GetOrder(orderID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// Check if the order can be canceled
-	if order.Status == "executed" {
-		return errors.New("cannot cancel an executed order")
+	if order.ParentID != "" && order.ParentID != parentID {
+		return nil, repository.ErrOrderNotFound
 	}
+	return order, nil
+}
 
-	// Update the order status to canceled
-	order.Status = "canceled"
+// commitOrder commits an event about the single order in ev.Order. Changes to
+// a scalper child are committed together with its parent so the parent status
+// and any linked legs stay derived from the children.
+func (s *OMSService) commitOrder(ev models.Event) error {
+	order := ev.Order
+	ev.OrderID = order.ID
+	if order.ParentID == "" {
+		return s.commit(ev)
+	}
 
-	// Save the updated order back to the repository
-	err = s.repo.UpdateOrder(order)
+	parent, err := s.repo.GetScalperOrder(order.ParentID)
 	if err != nil {
-		return errors.New("failed to update order")
+		return err
 	}
-
+	child := findChild(parent, order.ID)
+	if child == nil {
+		return repository.ErrOrderNotFound
+	}
+	*child = *order
+	sizeScalperOrder(parent)
+	if err := settle(parent); err != nil {
+		return err
+	}
+	ev.Order = nil
+	ev.ScalperOrder = parent
+	if err := s.commit(ev); err != nil {
+		return err
+	}
+	// Hand the caller the child as committed, with its new version.
+	*order = *child
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,130 @@
+package service
+
+import "github.com/Mukilan-T/laabhum-oms-go/models"
+
+// validateOrder fills in the defaults of a new order and checks it. Orders
+// default to the MIS product and the entry role; see normalizeTerms for the
+// pricing and validity defaults.
+func validateOrder(order *models.Order, now int64) error {
+	if order.Symbol == "" {
+		return invalidf("symbol is required")
+	}
+	if order.Side != "buy" && order.Side != "sell" {
+		return invalidf("side must be 'buy' or 'sell'")
+	}
+	if order.Quantity <= 0 {
+		return invalidf("quantity must be positive")
+	}
+
+	if order.Product == "" {
+		order.Product = models.ProductMIS
+	}
+	if !order.Product.Valid() {
+		return invalidf("unknown product type %q", order.Product)
+	}
+
+	switch order.Role {
+	case "":
+		order.Role = models.OrderRoleEntry
+	case models.OrderRoleEntry:
+	case models.OrderRoleExit, models.OrderRoleTarget, models.OrderRoleStopLoss:
+		if order.LinkedOrderID == "" {
+			return invalidf("a %s order must be linked to the entry it closes", order.Role)
+		}
+	default:
+		return invalidf("unknown order role %q", order.Role)
+	}
+	return normalizeTerms(order, now)
+}
+
+// normalizeTerms checks the order type, prices and time in force of an order.
+// An order without a type is a limit order if it has a price and a market
+// order otherwise; an order without a time in force is a DAY order.
+func normalizeTerms(order *models.Order, now int64) error {
+	if order.OrderType == "" {
+		order.OrderType = models.OrderTypeMarket
+		if order.Price > 0 {
+			order.OrderType = models.OrderTypeLimit
+		}
+	}
+	if order.Price < 0 || order.TriggerPrice < 0 || order.TrailAmount < 0 || order.TrailPercent < 0 {
+		return invalidf("prices and trails must not be negative")
+	}
+	if order.OrderType != models.OrderTypeTrailingStop && (order.TrailAmount != 0 || order.TrailPercent != 0) {
+		return invalidf("only %s orders take a trail", models.OrderTypeTrailingStop)
+	}
+	// Only the OMS sets Triggered, when the trigger price trades, and the
+	// broker fields, from execution reports.
+	order.Triggered = false
+	order.BrokerOrderID, order.BrokerUpdated = "", 0
+
+	switch order.OrderType {
+	case models.OrderTypeMarket:
+		if order.Price != 0 || order.TriggerPrice != 0 {
+			return invalidf("market orders take neither a price nor a trigger price")
+		}
+	case models.OrderTypeLimit:
+		if order.Price == 0 {
+			return invalidf("limit orders need a price")
+		}
+		if order.TriggerPrice != 0 {
+			return invalidf("limit orders take no trigger price")
+		}
+	case models.OrderTypeStop:
+		if order.TriggerPrice == 0 {
+			return invalidf("stop orders need a trigger price")
+		}
+		if order.Price != 0 {
+			return invalidf("stop orders take no price; use stop_limit")
+		}
+	case models.OrderTypeStopLimit:
+		if order.TriggerPrice == 0 || order.Price == 0 {
+			return invalidf("stop_limit orders need a price and a trigger price")
+		}
+		// A buy stop triggers as the price rises and then buys at most at
+		// Price, so the limit may not sit below the trigger; conversely
+		// for sells.
+		if order.Side == "buy" && order.Price < order.TriggerPrice {
+			return invalidf("buy stop_limit price must not be below the trigger price")
+		}
+		if order.Side == "sell" && order.Price > order.TriggerPrice {
+			return invalidf("sell stop_limit price must not be above the trigger price")
+		}
+	case models.OrderTypeTrailingStop:
+		if (order.TrailAmount == 0) == (order.TrailPercent == 0) {
+			return invalidf("trailing_stop orders need either a trail_amount or a trail_percent")
+		}
+		if order.TrailPercent >= 100 {
+			return invalidf("trail_percent must be below 100")
+		}
+		if order.Price != 0 {
+			return invalidf("trailing_stop orders take no price")
+		}
+	default:
+		return invalidf("unknown order type %q", order.OrderType)
+	}
+
+	if order.TimeInForce == "" {
+		order.TimeInForce = models.TimeInForceDay
+	}
+	switch order.TimeInForce {
+	case models.TimeInForceDay, models.TimeInForceGTC:
+		if order.ExpiresAt != 0 {
+			return invalidf("expires_at is only valid for %s orders", models.TimeInForceGTD)
+		}
+	case models.TimeInForceIOC:
+		if order.ExpiresAt != 0 {
+			return invalidf("expires_at is only valid for %s orders", models.TimeInForceGTD)
+		}
+		if isStopType(order.OrderType) {
+			return invalidf("stop orders cannot be IOC")
+		}
+	case models.TimeInForceGTD:
+		if order.ExpiresAt <= now {
+			return invalidf("GTD orders need an expires_at in the future")
+		}
+	default:
+		return invalidf("unknown time in force %q", order.TimeInForce)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// RelayOutbox delivers up to limit queued order events to every publisher and
+// marks them sent. It returns how many events were delivered. On a publish
+// failure nothing is marked, so the whole batch is retried on the next call:
+// delivery is at least once, and consumers deduplicate on OrderEvent.ID.
+func (s *OMSService) RelayOutbox(limit int) (int, error) {
+	s.relayMu.Lock()
+	defer s.relayMu.Unlock()
+
+	events, err := s.repo.PendingOutbox(limit)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	for _, publisher := range s.publishers {
+		if err := publisher.Publish(events); err != nil {
+			return 0, err
+		}
+	}
+
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.commit(models.Event{Type: models.EventOutboxRelayed, RelayedEvents: ids}); err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// RunOutboxRelay relays the outbox in batches of batchSize until ctx is done.
+// It runs as soon as a commit queues events, and every retry interval
+// otherwise, which is also how long it waits after a failed delivery.
+func (s *OMSService) RunOutboxRelay(ctx context.Context, batchSize int, retry time.Duration) {
+	ticker := time.NewTicker(retry)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := s.RelayOutbox(batchSize)
+			if err != nil {
+				log.Printf("ERROR: relaying order events: %v", err)
+				break
+			}
+			if n < batchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.relayWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// wakeRelay nudges RunOutboxRelay without blocking the caller.
+func (s *OMSService) wakeRelay() {
+	select {
+	case s.relayWake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// PositionBook keeps the net position per account, symbol and product type,
+// built up trade by trade.
+// Realized P&L is booked on the average-price method: closing quantity
+// realizes the difference between its price and the average open price.
+type PositionBook struct {
+	mu        sync.RWMutex
+	positions map[string]*models.Position // keyed by positionKey
+
+	// The P&L each account realized before sessionOpen, and what it has
+	// realized since, so the P&L of the session is a difference.
+	sessionOpen    int64
+	realizedAtOpen map[string]float64       // keyed by account ID
+	realizedSince  map[string][]realization // keyed by account ID
+}
+
+// realization is the P&L realized by one trade.
+type realization struct {
+	timestamp int64
+	pnl       float64
+}
+
+// NewPositionBook returns an empty position book.
+func NewPositionBook() *PositionBook {
+	return &PositionBook{
+		positions:      make(map[string]*models.Position),
+		realizedAtOpen: make(map[string]float64),
+		realizedSince:  make(map[string][]realization),
+	}
+}
+
+// Apply adds one trade to the book.
+func (b *PositionBook) Apply(trade models.Trade) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.apply(trade)
+}
+
+// Reset replaces the contents of the book with the positions built from
+// trades. The trades are applied oldest first, whatever their order.
+func (b *PositionBook) Reset(trades []models.Trade) {
+	sorted := append([]models.Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp < sorted[j].Timestamp
+	})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.positions = make(map[string]*models.Position)
+	b.sessionOpen = 0
+	b.realizedAtOpen = make(map[string]float64)
+	b.realizedSince = make(map[string][]realization)
+	for _, trade := range sorted {
+		b.apply(trade)
+	}
+}
+
+// RealizedBefore returns the P&L accountID realized before opened, the open
+// of the current trading session. Sessions only move forward: asked for an
+// earlier open than before, it answers for the later one.
+func (b *PositionBook) RealizedBefore(accountID string, opened int64) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if opened > b.sessionOpen {
+		for account, realized := range b.realizedSince {
+			var kept []realization
+			for _, r := range realized {
+				if r.timestamp < opened {
+					b.realizedAtOpen[account] += r.pnl
+				} else {
+					kept = append(kept, r)
+				}
+			}
+			b.realizedSince[account] = kept
+		}
+		b.sessionOpen = opened
+	}
+	return b.realizedAtOpen[accountID]
+}
+
+func (b *PositionBook) apply(trade models.Trade) {
+	product := trade.Product
+	if product == "" {
+		product = models.ProductMIS
+	}
+	id := models.PositionID(trade.Symbol, product)
+	key := positionKey(trade.AccountID, id)
+	position, ok := b.positions[key]
+	if !ok {
+		position = &models.Position{ID: id, AccountID: trade.AccountID, Symbol: trade.Symbol, Product: product}
+		b.positions[key] = position
+	}
+
+	// Conversion trades move quantity between products; they are not buys
+	// or sells in their own right.
+	quantity := trade.Quantity
+	if trade.Side == "sell" {
+		quantity = -quantity
+		if !trade.Conversion {
+			position.SellQuantity += trade.Quantity
+		}
+	} else if !trade.Conversion {
+		position.BuyQuantity += trade.Quantity
+	}
+	if trade.Timestamp > position.UpdatedAt {
+		position.UpdatedAt = trade.Timestamp
+	}
+
+	open := position.Quantity
+	switch {
+	case open == 0 || (open > 0) == (quantity > 0):
+		// Opening or adding: average the prices.
+		total := math.Abs(float64(open)) + math.Abs(float64(quantity))
+		position.AvgPrice = (position.AvgPrice*math.Abs(float64(open)) + trade.Price*math.Abs(float64(quantity))) / total
+		position.Quantity += quantity
+	default:
+		// Reducing, and possibly reversing, the position.
+		closed := minInt(absInt(open), absInt(quantity))
+		direction := 1.0
+		if open < 0 {
+			direction = -1
+		}
+		pnl := float64(closed) * (trade.Price - position.AvgPrice) * direction
+		position.RealizedPnL += pnl
+		if trade.Timestamp < b.sessionOpen {
+			b.realizedAtOpen[trade.AccountID] += pnl
+		} else {
+			b.realizedSince[trade.AccountID] = append(b.realizedSince[trade.AccountID], realization{trade.Timestamp, pnl})
+		}
+		position.Quantity += quantity
+		switch {
+		case position.Quantity == 0:
+			position.AvgPrice = 0
+		case (position.Quantity > 0) != (open > 0):
+			position.AvgPrice = trade.Price
+		}
+	}
+}
+
+// Positions returns a copy of every position, sorted by account and ID. A
+// non-empty accountID, symbol or product restricts the result to matching
+// positions. Open quantity is marked to the last traded prices in prices.
+func (b *PositionBook) Positions(accountID, symbol string, product models.ProductType, prices *PriceBook) []models.Position {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var positions []models.Position
+	for _, position := range b.positions {
+		if accountID != "" && position.AccountID != accountID {
+			continue
+		}
+		if symbol != "" && position.Symbol != symbol {
+			continue
+		}
+		if product != "" && position.Product != product {
+			continue
+		}
+		positions = append(positions, mark(*position, prices))
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].AccountID != positions[j].AccountID {
+			return positions[i].AccountID < positions[j].AccountID
+		}
+		return positions[i].ID < positions[j].ID
+	})
+	return positions
+}
+
+// Position returns the position of accountID with the given ID, if the book
+// has one.
+func (b *PositionBook) Position(accountID, id string, prices *PriceBook) (models.Position, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	position, ok := b.positions[positionKey(accountID, id)]
+	if !ok {
+		return models.Position{}, false
+	}
+	return mark(*position, prices), true
+}
+
+// positionKey keys the position id of accountID in a PositionBook. Position
+// IDs only identify a position within its account.
+func positionKey(accountID, id string) string {
+	return accountID + "\x00" + id
+}
+
+// mark fills in the unrealized P&L of p at the last traded price.
+func mark(p models.Position, prices *PriceBook) models.Position {
+	if ltp, ok := prices.LastPrice(p.Symbol); ok {
+		p.LastPrice = ltp
+		p.UnrealizedPnL = float64(p.Quantity) * (ltp - p.AvgPrice)
+	}
+	return p
+}
+
+// GetPositions returns the OMS positions, optionally only those of accountID,
+// in symbol and/or under product.
+func (s *OMSService) GetPositions(accountID, symbol string, product models.ProductType) []models.Position {
+	return s.positions.Positions(accountID, symbol, product, s.prices)
+}
+
+// SyncPositions rebuilds the position book from every trade in the
+// repository, discarding whatever it held before.
+func (s *OMSService) SyncPositions() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trades, err := s.repo.ListTrades()
+	if err != nil {
+		return err
+	}
+	s.positions.Reset(trades)
+	for _, trade := range trades {
+		s.prices.Update(trade.Symbol, trade.Price)
+	}
+	return nil
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,34 @@
+package service
+
+import "sync"
+
+// PriceBook holds the last traded price of every symbol the OMS has seen.
+// It is fed by recorded fills and by explicit price updates.
+type PriceBook struct {
+	mu     sync.RWMutex
+	prices map[string]float64
+}
+
+// NewPriceBook returns an empty price book.
+func NewPriceBook() *PriceBook {
+	return &PriceBook{prices: make(map[string]float64)}
+}
+
+// Update records price as the last traded price of symbol. Non-positive
+// prices are ignored.
+func (b *PriceBook) Update(symbol string, price float64) {
+	if symbol == "" || price <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.prices[symbol] = price
+}
+
+// LastPrice returns the last traded price of symbol, if one is known.
+func (b *PriceBook) LastPrice(symbol string) (float64, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	price, ok := b.prices[symbol]
+	return price, ok
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/google/uuid"
+)
+
+// EventPublisher delivers order lifecycle events to downstream consumers.
+type EventPublisher interface {
+	Publish(events []models.OrderEvent) error
+}
+
+// WithEventPublisher makes the service publish an OrderEvent for every order
+// each mutation changes. Events go through the outbox, so they reach p only
+// while RunOutboxRelay runs. Several publishers may be added; each receives
+// every event.
+func WithEventPublisher(p EventPublisher) Option {
+	return func(s *OMSService) {
+		s.publishers = append(s.publishers, p)
+	}
+}
+
+// orderEvents describes, as lifecycle events, how ev changes each order it
+// carries. It compares against the repository, so it must run before ev is
+// applied. Orders ev leaves unchanged produce no event.
+func (s *OMSService) orderEvents(ev models.Event) ([]models.OrderEvent, error) {
+	var orders []models.Order
+	if ev.Order != nil {
+		orders = append(orders, *ev.Order)
+	}
+	if ev.ScalperOrder != nil {
+		orders = append(orders, ev.ScalperOrder.ChildOrders...)
+	}
+
+	var events []models.OrderEvent
+	for _, order := range orders {
+		// A lookup error means the order is new to the repository.
+		prev, _ := s.repo.GetOrder(order.ID)
+		kind, changed, err := lifecycleKind(prev, order)
+		if err != nil {
+			return nil, err
+		}
+		if !changed {
+			continue
+		}
+		event := models.OrderEvent{
+			Version:   models.OrderEventVersion,
+			ID:        uuid.NewString(),
+			Kind:      kind,
+			OrderID:   order.ID,
+			ParentID:  order.ParentID,
+			AccountID: order.AccountID,
+			Timestamp: ev.Timestamp,
+			Order:     order,
+		}
+		for _, trade := range ev.Trades {
+			if trade.OrderID == order.ID {
+				event.Trades = append(event.Trades, trade)
+			}
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// lifecycleKind classifies the change from prev, nil for a new order, to next.
+func lifecycleKind(prev *models.Order, next models.Order) (models.OrderEventKind, bool, error) {
+	if prev == nil {
+		if next.Status == models.OrderStatusRejected {
+			return models.OrderEventRejected, true, nil
+		}
+		return models.OrderEventCreated, true, nil
+	}
+
+	changed, err := differs(prev, next)
+	if err != nil || !changed {
+		return "", false, err
+	}
+
+	switch {
+	case next.FilledQuantity > prev.FilledQuantity:
+		return models.OrderEventFilled, true, nil
+	case next.Triggered && !prev.Triggered:
+		return models.OrderEventTriggered, true, nil
+	case prev.Status == next.Status:
+		return models.OrderEventModified, true, nil
+	}
+	switch next.Status {
+	case models.OrderStatusCanceled, models.OrderStatusExpired:
+		return models.OrderEventCanceled, true, nil
+	case models.OrderStatusRejected:
+		return models.OrderEventRejected, true, nil
+	}
+	return models.OrderEventModified, true, nil
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// ErrInvalidQuery is returned for order queries with a bad filter, sort or
+// cursor.
+var ErrInvalidQuery = invalidf("invalid order query")
+
+// Page sizes of QueryOrders.
+const (
+	DefaultOrderPageSize = 100
+	MaxOrderPageSize     = 1000
+)
+
+// OrderFilter selects orders. Zero fields match every order.
+type OrderFilter struct {
+	AccountID   string
+	ParentID    string
+	Symbol      string
+	Side        string
+	Statuses    []models.OrderStatus // Any of them
+	CreatedFrom int64                // Inclusive, in Unix seconds
+	CreatedTo   int64                // Exclusive, in Unix seconds
+}
+
+// Match reports whether order passes f.
+func (f OrderFilter) Match(order models.Order) bool {
+	switch {
+	case f.AccountID != "" && order.AccountID != f.AccountID,
+		f.ParentID != "" && order.ParentID != f.ParentID,
+		f.Symbol != "" && !strings.EqualFold(order.Symbol, f.Symbol),
+		f.Side != "" && order.Side != f.Side,
+		f.CreatedFrom != 0 && order.CreatedAt < f.CreatedFrom,
+		f.CreatedTo != 0 && order.CreatedAt >= f.CreatedTo:
+		return false
+	}
+	if len(f.Statuses) == 0 {
+		return true
+	}
+	for _, status := range f.Statuses {
+		if order.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderQuery asks for one page of the orders matching Filter.
+type OrderQuery struct {
+	Filter OrderFilter
+	// Sort is the field to order by: created_at, updated_at, symbol or
+	// status, prefixed with "-" for descending order. Ties are broken by
+	// order ID. The default is created_at.
+	Sort string
+	// Limit is the page size, DefaultOrderPageSize when zero.
+	Limit int
+	// Cursor continues after the page that returned it as NextCursor.
+	Cursor string
+}
+
+// OrderPage is one page of a QueryOrders result.
+type OrderPage struct {
+	Orders []models.Order `json:"orders"`
+	// NextCursor fetches the next page; it is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// orderSortKeys renders the value an order is sorted by as a string that
+// sorts the same way.
+var orderSortKeys = map[string]func(models.Order) string{
+	"created_at": func(o models.Order) string { return fmt.Sprintf("%020d", o.CreatedAt) },
+	"updated_at": func(o models.Order) string { return fmt.Sprintf("%020d", o.UpdatedAt) },
+	"symbol":     func(o models.Order) string { return o.Symbol },
+	"status":     func(o models.Order) string { return string(o.Status) },
+}
+
+// orderCursor is the position of the last order of a page in its sort order.
+// Pages continue from the position rather than an offset, so orders created
+// between two pages neither repeat nor skip entries.
+type orderCursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   string `json:"id"`
+}
+
+// QueryOrders returns one page of the orders matching q.Filter, sorted by
+// q.Sort.
+func (s *OMSService) QueryOrders(q OrderQuery) (*OrderPage, error) {
+	if q.Sort == "" {
+		q.Sort = "created_at"
+	}
+	field := strings.TrimPrefix(q.Sort, "-")
+	descending := field != q.Sort
+	sortKey, ok := orderSortKeys[field]
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot sort by %q", ErrInvalidQuery, q.Sort)
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultOrderPageSize
+	}
+	if q.Limit < 0 || q.Limit > MaxOrderPageSize {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxOrderPageSize)
+	}
+	var after *orderCursor
+	if q.Cursor != "" {
+		cursor, err := decodeOrderCursor(q.Cursor)
+		if err != nil || cursor.Sort != q.Sort {
+			return nil, fmt.Errorf("%w: cursor does not belong to this query", ErrInvalidQuery)
+		}
+		after = &cursor
+	}
+
+	orders, err := s.repo.GetOrders()
+	if err != nil {
+		return nil, err
+	}
+	// before reports whether the order at (key, id) comes before the one at
+	// (otherKey, otherID) in the requested order.
+	before := func(key, id, otherKey, otherID string) bool {
+		if key != otherKey {
+			return (key < otherKey) != descending
+		}
+		return id != otherID && (id < otherID) != descending
+	}
+
+	page := &OrderPage{Orders: []models.Order{}}
+	var keys []string
+	for _, order := range orders {
+		if !q.Filter.Match(order) {
+			continue
+		}
+		key := sortKey(order)
+		if after != nil && !before(after.Key, after.ID, key, order.ID) {
+			continue
+		}
+		page.Orders = append(page.Orders, order)
+		keys = append(keys, key)
+	}
+	sort.Sort(ordersByKey{orders: page.Orders, keys: keys, before: before})
+
+	if len(page.Orders) > q.Limit {
+		last := q.Limit - 1
+		page.NextCursor = encodeOrderCursor(orderCursor{Sort: q.Sort, Key: keys[last], ID: page.Orders[last].ID})
+		page.Orders = page.Orders[:q.Limit]
+	}
+	return page, nil
+}
+
+// ordersByKey sorts orders together with their sort keys.
+type ordersByKey struct {
+	orders []models.Order
+	keys   []string
+	before func(key, id, otherKey, otherID string) bool
+}
+
+func (o ordersByKey) Len() int {
+	return len(o.orders)
+}
+
+func (o ordersByKey) Less(i, j int) bool {
+	return o.before(o.keys[i], o.orders[i].ID, o.keys[j], o.orders[j].ID)
+}
+
+func (o ordersByKey) Swap(i, j int) {
+	o.orders[i], o.orders[j] = o.orders[j], o.orders[i]
+	o.keys[i], o.keys[j] = o.keys[j], o.keys[i]
+}
+
+func encodeOrderCursor(c orderCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeOrderCursor(s string) (orderCursor, error) {
+	var c orderCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// RiskCode identifies the pre-trade risk check that rejected an order.
+type RiskCode string
+
+const (
+	RiskRestrictedSymbol RiskCode = "restricted_symbol"
+	RiskMaxQuantity      RiskCode = "max_quantity"
+	RiskMaxNotional      RiskCode = "max_notional"
+	RiskPriceBand        RiskCode = "price_band"
+	RiskMaxPosition      RiskCode = "max_position"
+	RiskMaxDailyLoss     RiskCode = "max_daily_loss"
+	RiskOrderRate        RiskCode = "order_rate"
+	RiskKillSwitch       RiskCode = "kill_switch"
+)
+
+// RiskError is returned when a pre-trade risk check rejects an order. OrderID
+// is the rejected order as recorded by the OMS, when it was recorded.
+type RiskError struct {
+	Code    RiskCode `json:"code"`
+	Reason  string   `json:"reason"`
+	OrderID string   `json:"order_id,omitempty"`
+}
+
+func (e *RiskError) Error() string {
+	return fmt.Sprintf("rejected by risk check %s: %s", e.Code, e.Reason)
+}
+
+func reject(code RiskCode, format string, args ...interface{}) error {
+	return &RiskError{Code: code, Reason: fmt.Sprintf(format, args...)}
+}
+
+// RiskState gives risk checks read access to the OMS state an order is
+// checked against.
+type RiskState interface {
+	// LastPrice returns the last traded price of symbol, if one is known.
+	LastPrice(symbol string) (float64, bool)
+	// NetQuantity is the net open quantity of accountID in symbol across
+	// product types, positive when long.
+	NetQuantity(accountID, symbol string) int
+	// DayPnL is the P&L accountID realized since the session opened plus the
+	// unrealized P&L of its open positions.
+	DayPnL(accountID string) (float64, error)
+	// Now is the time the order is checked at.
+	Now() time.Time
+}
+
+// RiskCheck inspects an order before the OMS accepts it. Returning an error,
+// normally a *RiskError, rejects the order.
+type RiskCheck interface {
+	Check(order models.Order, state RiskState) error
+}
+
+// RiskCheckFunc adapts a function to RiskCheck.
+type RiskCheckFunc func(order models.Order, state RiskState) error
+
+func (f RiskCheckFunc) Check(order models.Order, state RiskState) error {
+	return f(order, state)
+}
+
+// WithRiskChecks adds checks to the chain every new order must pass. Checks
+// run in the order they were added and the first rejection wins.
+func WithRiskChecks(checks ...RiskCheck) Option {
+	return func(s *OMSService) {
+		s.riskChecks = append(s.riskChecks, checks...)
+	}
+}
+
+// RiskLimits configures the built-in risk checks. Zero values disable a check.
+type RiskLimits struct {
+	RestrictedSymbols  []string
+	MaxQuantity        int
+	MaxNotional        float64
+	PriceBandPercent   float64 // Largest distance of an order price from the last traded price
+	MaxPosition        int     // Largest net open quantity per account and symbol
+	MaxDailyLoss       float64 // Per account
+	MaxOrdersPerSecond int     // Per account
+}
+
+// RiskChecks returns the chain of built-in checks enabled by limits.
+func RiskChecks(limits RiskLimits) []RiskCheck {
+	var checks []RiskCheck
+	if len(limits.RestrictedSymbols) > 0 {
+		checks = append(checks, NewRestrictedSymbolsCheck(limits.RestrictedSymbols))
+	}
+	if limits.MaxQuantity > 0 {
+		checks = append(checks, MaxQuantityCheck{Max: limits.MaxQuantity})
+	}
+	if limits.MaxNotional > 0 {
+		checks = append(checks, MaxNotionalCheck{Max: limits.MaxNotional})
+	}
+	if limits.PriceBandPercent > 0 {
+		checks = append(checks, PriceBandCheck{Percent: limits.PriceBandPercent})
+	}
+	if limits.MaxPosition > 0 {
+		checks = append(checks, MaxPositionCheck{Max: limits.MaxPosition})
+	}
+	if limits.MaxDailyLoss > 0 {
+		checks = append(checks, DailyLossCheck{Max: limits.MaxDailyLoss})
+	}
+	if limits.MaxOrdersPerSecond > 0 {
+		checks = append(checks, NewOrderRateCheck(limits.MaxOrdersPerSecond))
+	}
+	return checks
+}
+
+// RestrictedSymbolsCheck rejects orders in any of a list of symbols.
+type RestrictedSymbolsCheck struct {
+	symbols map[string]bool
+}
+
+// NewRestrictedSymbolsCheck restricts the given symbols, case-insensitively.
+func NewRestrictedSymbolsCheck(symbols []string) *RestrictedSymbolsCheck {
+	c := &RestrictedSymbolsCheck{symbols: make(map[string]bool)}
+	for _, symbol := range symbols {
+		c.symbols[strings.ToUpper(symbol)] = true
+	}
+	return c
+}
+
+func (c *RestrictedSymbolsCheck) Check(order models.Order, _ RiskState) error {
+	if c.symbols[strings.ToUpper(order.Symbol)] {
+		return reject(RiskRestrictedSymbol, "%s is on the restricted list", order.Symbol)
+	}
+	return nil
+}
+
+// MaxQuantityCheck caps the quantity of a single order.
+type MaxQuantityCheck struct {
+	Max int
+}
+
+func (c MaxQuantityCheck) Check(order models.Order, _ RiskState) error {
+	if order.Quantity > c.Max {
+		return reject(RiskMaxQuantity, "quantity %d exceeds the limit of %d", order.Quantity, c.Max)
+	}
+	return nil
+}
+
+// MaxNotionalCheck caps the value of a single order. Orders are valued at
+// their price, or trigger price, or else the last traded price; an order that
+// cannot be valued is rejected.
+type MaxNotionalCheck struct {
+	Max float64
+}
+
+func (c MaxNotionalCheck) Check(order models.Order, state RiskState) error {
+	price := order.Price
+	if price == 0 {
+		price = order.TriggerPrice
+	}
+	if price == 0 {
+		ltp, ok := state.LastPrice(order.Symbol)
+		if !ok {
+			return reject(RiskMaxNotional, "no price to value the order at")
+		}
+		price = ltp
+	}
+	if notional := price * float64(order.Quantity); notional > c.Max {
+		return reject(RiskMaxNotional, "notional %.2f exceeds the limit of %.2f", notional, c.Max)
+	}
+	return nil
+}
+
+// PriceBandCheck rejects orders priced, or triggered, further than Percent
+// away from the last traded price. Orders in symbols without a last traded
+// price pass.
+type PriceBandCheck struct {
+	Percent float64
+}
+
+func (c PriceBandCheck) Check(order models.Order, state RiskState) error {
+	ltp, ok := state.LastPrice(order.Symbol)
+	if !ok {
+		return nil
+	}
+	for _, price := range []float64{order.Price, order.TriggerPrice} {
+		if price == 0 {
+			continue
+		}
+		if away := math.Abs(price-ltp) / ltp * 100; away > c.Percent {
+			return reject(RiskPriceBand, "price %g is %.2f%% away from the last traded price %g, limit %g%%", price, away, ltp, c.Percent)
+		}
+	}
+	return nil
+}
+
+// MaxPositionCheck caps the net open quantity per symbol the order would
+// leave its account with if what it has left filled. Orders that reduce the
+// position always pass.
+type MaxPositionCheck struct {
+	Max int
+}
+
+func (c MaxPositionCheck) Check(order models.Order, state RiskState) error {
+	open := state.NetQuantity(order.AccountID, order.Symbol)
+	remaining := order.RemainingQuantity()
+	after := open + remaining
+	if order.Side == "sell" {
+		after = open - remaining
+	}
+	if absInt(after) > c.Max && absInt(after) > absInt(open) {
+		return reject(RiskMaxPosition, "position in %s would reach %d, limit %d", order.Symbol, after, c.Max)
+	}
+	return nil
+}
+
+// DailyLossCheck stops new entries once the day's loss of the order's account
+// reaches Max. Exits and other closing orders still pass so positions can be
+// flattened.
+type DailyLossCheck struct {
+	Max float64
+}
+
+func (c DailyLossCheck) Check(order models.Order, state RiskState) error {
+	if !order.IsEntry() {
+		return nil
+	}
+	pnl, err := state.DayPnL(order.AccountID)
+	if err != nil {
+		return err
+	}
+	if -pnl >= c.Max {
+		return reject(RiskMaxDailyLoss, "day loss %.2f has reached the limit of %.2f", -pnl, c.Max)
+	}
+	return nil
+}
+
+// OrderRateCheck limits how many orders each account may place in any one
+// second. Every order that reaches the check counts.
+type OrderRateCheck struct {
+	perSecond int
+
+	mu     sync.Mutex
+	recent map[string][]time.Time // per account, oldest first
+}
+
+// NewOrderRateCheck allows perSecond orders per account per second.
+func NewOrderRateCheck(perSecond int) *OrderRateCheck {
+	return &OrderRateCheck{perSecond: perSecond, recent: make(map[string][]time.Time)}
+}
+
+func (c *OrderRateCheck) Check(order models.Order, state RiskState) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := state.Now()
+	window := c.recent[order.AccountID]
+	start := sort.Search(len(window), func(i int) bool {
+		return now.Sub(window[i]) < time.Second
+	})
+	window = window[start:]
+	if len(window) >= c.perSecond {
+		c.recent[order.AccountID] = window
+		return reject(RiskOrderRate, "more than %d orders per second", c.perSecond)
+	}
+	c.recent[order.AccountID] = append(window, now)
+	return nil
+}
+
+// checkRisk runs the kill switches and then the risk chain against order.
+// Callers must hold s.mu.
+func (s *OMSService) checkRisk(order models.Order, now time.Time) error {
+	if err := s.checkKillSwitch(order.AccountID); err != nil {
+		return err
+	}
+	state := riskState{s: s, now: now}
+	for _, check := range s.riskChecks {
+		if err := check.Check(order, state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// riskState is the RiskState of an OMSService.
+type riskState struct {
+	s   *OMSService
+	now time.Time
+}
+
+func (r riskState) LastPrice(symbol string) (float64, bool) {
+	return r.s.prices.LastPrice(symbol)
+}
+
+func (r riskState) NetQuantity(accountID, symbol string) int {
+	net := 0
+	for _, position := range r.s.positions.Positions(accountID, symbol, "", r.s.prices) {
+		net += position.Quantity
+	}
+	return net
+}
+
+func (r riskState) Now() time.Time {
+	return r.now
+}
+
+// DayPnL takes the P&L realized before the session opened from the total
+// of the account's positions.
+func (r riskState) DayPnL(accountID string) (float64, error) {
+	opened := r.s.session.closeAfter(r.now.Unix()).AddDate(0, 0, -1).Unix()
+	pnl := -r.s.positions.RealizedBefore(accountID, opened)
+	for _, position := range r.s.positions.Positions(accountID, "", "", r.s.prices) {
+		pnl += position.RealizedPnL + position.UnrealizedPnL
+	}
+	return pnl, nil
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/google/uuid"
+)
+
+// SlicingRule controls how a scalper order's quantity is split into child
+// legs. Legs is the number of children to aim for; MaxChildQuantity, when
+// positive, caps the size of any one child and adds legs as needed.
+type SlicingRule struct {
+	Legs             int
+	MaxChildQuantity int
+}
+
+// WithSlicingRule sets the default rule used to split scalper orders.
+func WithSlicingRule(rule SlicingRule) Option {
+	return func(s *OMSService) {
+		s.slicing = rule
+	}
+}
+
+// sliceQuantity splits total into child quantities according to rule. legs,
+// when positive, overrides rule.Legs. Quantities differ by at most one, with
+// the larger ones first.
+func sliceQuantity(total int, rule SlicingRule, legs int) []int {
+	if legs <= 0 {
+		legs = rule.Legs
+	}
+	if legs <= 0 {
+		legs = 1
+	}
+	if rule.MaxChildQuantity > 0 {
+		if needed := (total + rule.MaxChildQuantity - 1) / rule.MaxChildQuantity; needed > legs {
+			legs = needed
+		}
+	}
+	if legs > total {
+		legs = total
+	}
+
+	quantities := make([]int, legs)
+	for i := range quantities {
+		quantities[i] = total / legs
+		if i < total%legs {
+			quantities[i]++
+		}
+	}
+	return quantities
+}
+
+func validateScalperOrder(order models.ScalperOrder) error {
+	if order.Symbol == "" {
+		return invalidf("symbol is required")
+	}
+	if order.ParentOrder.Side != "buy" && order.ParentOrder.Side != "sell" {
+		return invalidf("side must be 'buy' or 'sell'")
+	}
+	if order.Quantity <= 0 {
+		return invalidf("quantity must be positive")
+	}
+	if order.Legs < 0 {
+		return invalidf("legs must not be negative")
+	}
+	if !order.ParentOrder.Product.Valid() {
+		return invalidf("unknown product type %q", order.ParentOrder.Product)
+	}
+	return nil
+}
+
+// CreateScalperOrder accepts a parent order and generates its child legs. Any
+// child orders sent by the client are ignored; the OMS owns the slicing.
+func (s *OMSService) CreateScalperOrder(order models.ScalperOrder) (*models.ScalperOrder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The top-level fields and the parent order template may each carry the
+	// symbol, quantity and owner; prefer the top-level ones and keep both in
+	// sync.
+	if order.Symbol == "" {
+		order.Symbol = order.ParentOrder.Symbol
+	}
+	if order.Quantity == 0 {
+		order.Quantity = order.ParentOrder.Quantity
+	}
+	if order.AccountID == "" {
+		order.AccountID = order.ParentOrder.AccountID
+	}
+	if order.UserID == "" {
+		order.UserID = order.ParentOrder.UserID
+	}
+	if order.ParentOrder.Product == "" {
+		order.ParentOrder.Product = models.ProductMIS
+	}
+	if err := validateScalperOrder(order); err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	if err := normalizeTerms(&order.ParentOrder, now); err != nil {
+		return nil, err
+	}
+
+	order.ID = uuid.NewString()
+	order.CreatedAt = now
+	order.ParentOrder.ID = order.ID
+	order.ParentOrder.Symbol = order.Symbol
+	order.ParentOrder.Quantity = order.Quantity
+	order.ParentOrder.AccountID = order.AccountID
+	order.ParentOrder.UserID = order.UserID
+	order.ParentOrder.Status = ""
+	order.ParentOrder.Transitions = nil
+	order.ParentOrder.CreatedAt = now
+
+	if err := s.checkRisk(order.ParentOrder, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// A bracket has a single entry, so resizing it resizes the bracket.
+	quantities := []int{order.Quantity}
+	if order.Bracket == nil {
+		quantities = sliceQuantity(order.Quantity, s.slicing, order.Legs)
+	} else if err := validateBracket(order); err != nil {
+		return nil, err
+	}
+
+	order.ChildOrders = nil
+	for i, quantity := range quantities {
+		child := models.Order{
+			ID:           uuid.NewString(),
+			ParentID:     order.ID,
+			Leg:          i + 1,
+			AccountID:    order.AccountID,
+			UserID:       order.UserID,
+			Symbol:       order.Symbol,
+			Quantity:     quantity,
+			Price:        order.ParentOrder.Price,
+			StopLoss:     order.ParentOrder.StopLoss,
+			OrderType:    order.ParentOrder.OrderType,
+			TriggerPrice: order.ParentOrder.TriggerPrice,
+			TrailAmount:  order.ParentOrder.TrailAmount,
+			TrailPercent: order.ParentOrder.TrailPercent,
+			TimeInForce:  order.ParentOrder.TimeInForce,
+			ExpiresAt:    order.ParentOrder.ExpiresAt,
+			Side:         order.ParentOrder.Side,
+			Product:      order.ParentOrder.Product,
+			Role:         models.OrderRoleEntry,
+			Status:       models.OrderStatusNew,
+			CreatedAt:    now,
+		}
+		if err := transition(&child, models.OrderStatusPending, "generated from scalper order"); err != nil {
+			return nil, err
+		}
+		order.ChildOrders = append(order.ChildOrders, child)
+	}
+	order.Legs = len(order.ChildOrders)
+	if order.Bracket != nil {
+		order.ChildOrders = append(order.ChildOrders, bracketLegs(order.ChildOrders[0], *order.Bracket, now)...)
+	}
+	deriveScalperStatus(&order)
+
+	if err := s.commit(models.Event{Type: models.EventScalperCreated, OrderID: order.ID, ScalperOrder: &order}); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetScalperOrder returns a scalper order together with its children.
+func (s *OMSService) GetScalperOrder(parentID string) (*models.ScalperOrder, error) {
+	return s.repo.GetScalperOrder(parentID)
+}
+
+// ExecuteChildOrder fills the remaining quantity of one child at its price on
+// behalf of actor.
+func (s *OMSService) ExecuteChildOrder(parentID, childID, actor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parentOrder, err := s.repo.GetScalperOrder(parentID)
+	if err != nil {
+		return err
+	}
+	child := findChild(parentOrder, childID)
+	if child == nil {
+		return repository.ErrOrderNotFound
+	}
+	trade, err := s.executeChild(child)
+	if err != nil {
+		return err
+	}
+	if err := settle(parentOrder); err != nil {
+		return err
+	}
+	return s.commit(models.Event{Type: models.EventChildExecuted, OrderID: childID, Actor: actor, ScalperOrder: parentOrder, Trades: []models.Trade{*trade}})
+}
+
+// ExecuteAllChildOrders fills every child of the parent that is still working.
+// One-cancels-other legs are skipped, since filling both would be wrong, and
+// so are legs that are not active yet.
+func (s *OMSService) ExecuteAllChildOrders(parentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parentOrder, err := s.repo.GetScalperOrder(parentID)
+	if err != nil {
+		return err
+	}
+	var trades []models.Trade
+	for i := range parentOrder.ChildOrders {
+		child := &parentOrder.ChildOrders[i]
+		if child.Status.IsTerminal() || child.Status == models.OrderStatusNew || isOCOLeg(*child) {
+			continue
+		}
+		trade, err := s.executeChild(child)
+		if err != nil {
+			return err
+		}
+		trades = append(trades, *trade)
+	}
+	if len(trades) == 0 {
+		return fmt.Errorf("%w: no working child orders to execute", ErrInvalidTransition)
+	}
+	if err := settle(parentOrder); err != nil {
+		return err
+	}
+	return s.commit(models.Event{Type: models.EventChildExecuted, OrderID: parentID, ScalperOrder: parentOrder, Trades: trades})
+}
+
+// CancelScalperOrder cancels every child of the parent that is still working.
+func (s *OMSService) CancelScalperOrder(parentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parentOrder, err := s.repo.GetScalperOrder(parentID)
+	if err != nil {
+		return err
+	}
+	canceled := 0
+	for i := range parentOrder.ChildOrders {
+		child := &parentOrder.ChildOrders[i]
+		if child.Status.IsTerminal() {
+			continue
+		}
+		if err := transition(child, models.OrderStatusCanceled, "parent canceled by user"); err != nil {
+			return err
+		}
+		canceled++
+	}
+	if canceled == 0 {
+		return fmt.Errorf("%w: no working child orders to cancel", ErrInvalidTransition)
+	}
+	if err := settle(parentOrder); err != nil {
+		return err
+	}
+	return s.commit(models.Event{Type: models.EventOrderCanceled, OrderID: parentID, ScalperOrder: parentOrder})
+}
+
+// executeChild fills whatever is left of a child order at its price, as a
+// manual execution with an OMS-generated execution id. Orders without a price,
+// such as market exits, fill at the last traded price.
+func (s *OMSService) executeChild(child *models.Order) (*models.Trade, error) {
+	if child.Status.IsTerminal() {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, child.Status, models.OrderStatusFilled)
+	}
+	price := child.Price
+	if price <= 0 {
+		ltp, ok := s.prices.LastPrice(child.Symbol)
+		if !ok {
+			return nil, conflictf("no last traded price to execute %s", child.ID)
+		}
+		price = ltp
+	}
+	return applyFill(child, models.Fill{
+		OrderID:     child.ID,
+		ExecutionID: "manual-" + uuid.NewString(),
+		Quantity:    child.RemainingQuantity(),
+		Price:       price,
+	})
+}
+
+// findChild returns a pointer into parent.ChildOrders, or nil.
+func findChild(parent *models.ScalperOrder, childID string) *models.Order {
+	for i := range parent.ChildOrders {
+		if parent.ChildOrders[i].ID == childID {
+			return &parent.ChildOrders[i]
+		}
+	}
+	return nil
+}
+
+// sizeScalperOrder sets the parent's quantity to the total of its entry
+// children, which an amendment of a child may have changed.
+func sizeScalperOrder(parent *models.ScalperOrder) {
+	quantity := 0
+	for _, child := range parent.ChildOrders {
+		if child.IsEntry() {
+			quantity += child.Quantity
+		}
+	}
+	parent.Quantity = quantity
+	parent.ParentOrder.Quantity = quantity
+}
+
+// deriveScalperStatus sets the parent's status from the state of its entry
+// children: fully executed once everything is filled, partially executed once
+// anything is, canceled when every child ended without a fill, open otherwise.
+// Exit, target and stop-loss children do not count towards the parent.
+func deriveScalperStatus(parent *models.ScalperOrder) {
+	filled, working, entries := 0, 0, 0
+	for _, child := range parent.ChildOrders {
+		if !child.IsEntry() {
+			continue
+		}
+		entries++
+		filled += child.FilledQuantity
+		if !child.Status.IsTerminal() {
+			working++
+		}
+	}
+
+	switch {
+	case filled >= parent.Quantity:
+		parent.Status = models.ScalperStatusFullyExecuted
+	case filled > 0:
+		parent.Status = models.ScalperStatusPartiallyExecuted
+	case working == 0 && entries > 0:
+		parent.Status = models.ScalperStatusCanceled
+	default:
+		parent.Status = models.ScalperStatusOpen
+	}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+)
+
+// OnPrice records price as the last traded price of symbol and works the stop
+// orders resting on it: trailing stops ratchet their trigger towards the price
+// and every stop whose trigger price has traded is triggered. A triggered stop
+// or trailing stop works as a market order from then on, a stop-limit as a
+// limit order at its price. No separate exit order is generated: the stop is
+// the exit, and is published as an OrderEventTriggered for the broker to
+// work. A filled scalper entry without a stop-loss leg is guarded by its
+// StopLoss instead, and is exited at the market once that price trades.
+// OnPrice returns the stops it triggered and the exits it generated.
+func (s *OMSService) OnPrice(symbol string, price float64) ([]models.Order, error) {
+	if symbol == "" || price <= 0 {
+		return nil, invalidf("a price update needs a symbol and a positive price")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prices.Update(symbol, price)
+	if s.stops == nil {
+		if err := s.loadStops(); err != nil {
+			return nil, err
+		}
+	}
+	var orders []models.Order
+	for id := range s.stops[symbol] {
+		order, err := s.repo.GetOrder(id)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].CreatedAt != orders[j].CreatedAt {
+			return orders[i].CreatedAt < orders[j].CreatedAt
+		}
+		return orders[i].ID < orders[j].ID
+	})
+
+	var triggered []models.Order
+	for _, listed := range orders {
+		// Triggering a stop-loss leg can settle its bracket, so work from
+		// the stored state rather than the listing.
+		order, err := s.repo.GetOrder(listed.ID)
+		if err != nil {
+			return triggered, err
+		}
+		if order.Symbol != symbol || !watched(*order) {
+			delete(s.stops[symbol], order.ID)
+			continue
+		}
+		if !armed(*order) {
+			exits, err := s.stopOut(order, price)
+			if err != nil {
+				return triggered, err
+			}
+			triggered = append(triggered, exits...)
+			continue
+		}
+
+		eventType := models.EventType("")
+		if trail(order, price) {
+			eventType = models.EventStopTrailed
+		}
+		if breached(*order, price) {
+			order.Triggered = true
+			order.UpdatedAt = time.Now().Unix()
+			if order.Status == models.OrderStatusPending {
+				reason := fmt.Sprintf("triggered at %g (trigger %g)", price, order.TriggerPrice)
+				if err := transition(order, models.OrderStatusOpen, reason); err != nil {
+					return triggered, err
+				}
+			}
+			eventType = models.EventStopTriggered
+		}
+		if eventType == "" {
+			continue
+		}
+		if err := s.commitOrder(models.Event{Type: eventType, Order: order}); err != nil {
+			return triggered, err
+		}
+		if order.Triggered {
+			triggered = append(triggered, *order)
+		}
+	}
+	return triggered, nil
+}
+
+// stopOut exits entry, a guarded scalper child, at the market once price
+// reaches its StopLoss. A working stop-loss leg or exit already covers the
+// entry, so it is left alone then. stopOut returns the exit it generated, if
+// any. Callers must hold s.mu.
+func (s *OMSService) stopOut(entry *models.Order, price float64) ([]models.Order, error) {
+	parent, err := s.repo.GetScalperOrder(entry.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	open := entry.FilledQuantity
+	for _, closing := range parent.ChildOrders {
+		if closing.LinkedOrderID != entry.ID || closing.IsEntry() {
+			continue
+		}
+		if !closing.Status.IsTerminal() && (closing.Role == models.OrderRoleStopLoss || closing.Role == models.OrderRoleExit) {
+			return nil, nil
+		}
+		open -= closing.FilledQuantity
+	}
+	if open <= 0 {
+		if entry.Status.IsTerminal() {
+			// The position is closed; nothing is left to guard.
+			delete(s.stops[entry.Symbol], entry.ID)
+		}
+		return nil, nil
+	}
+	if !breached(models.Order{Side: oppositeSide(entry.Side), TriggerPrice: entry.StopLoss}, price) {
+		return nil, nil
+	}
+	return s.exitScalperOrder(parent, entry.ID)
+}
+
+// loadStops builds the index of armed stops and guarded entries from the
+// repository. Callers must hold s.mu.
+func (s *OMSService) loadStops() error {
+	orders, err := s.repo.GetOrders()
+	if err != nil {
+		return err
+	}
+	s.stops = make(map[string]map[string]bool)
+	for _, order := range orders {
+		s.indexStop(order)
+	}
+	return nil
+}
+
+// indexStop adds order to the index of stops if OnPrice has to watch it, and
+// removes it otherwise. Callers must hold s.mu.
+func (s *OMSService) indexStop(order models.Order) {
+	if s.stops == nil {
+		return
+	}
+	if !watched(order) {
+		delete(s.stops[order.Symbol], order.ID)
+		return
+	}
+	if s.stops[order.Symbol] == nil {
+		s.stops[order.Symbol] = make(map[string]bool)
+	}
+	s.stops[order.Symbol][order.ID] = true
+}
+
+func isStopType(orderType models.OrderType) bool {
+	switch orderType {
+	case models.OrderTypeStop, models.OrderTypeStopLimit, models.OrderTypeTrailingStop:
+		return true
+	}
+	return false
+}
+
+// armed reports whether order is a working stop waiting for its trigger.
+// Inactive bracket legs (status new) are not armed until their entry fills.
+func armed(order models.Order) bool {
+	return isStopType(order.OrderType) && !order.Triggered &&
+		order.Status != models.OrderStatusNew && !order.Status.IsTerminal()
+}
+
+// guarded reports whether order is a filled scalper entry with a StopLoss.
+// Whether a stop-loss leg or an exit already covers it is up to stopOut.
+func guarded(order models.Order) bool {
+	return order.ParentID != "" && order.IsEntry() && order.FilledQuantity > 0 && order.StopLoss > 0
+}
+
+// watched reports whether OnPrice has to look at order.
+func watched(order models.Order) bool {
+	return armed(order) || guarded(order)
+}
+
+// trail moves the trigger of a trailing stop to its trail distance from price
+// when that tightens the stop, and reports whether it moved. A trailing stop
+// without a trigger yet is anchored at the first price it sees.
+func trail(order *models.Order, price float64) bool {
+	if order.OrderType != models.OrderTypeTrailingStop {
+		return false
+	}
+	distance := order.TrailAmount
+	if order.TrailPercent > 0 {
+		distance = price * order.TrailPercent / 100
+	}
+	trigger := price - distance
+	if order.Side == "buy" {
+		trigger = price + distance
+	}
+	if order.TriggerPrice != 0 && !tightens(*order, trigger) {
+		return false
+	}
+	order.TriggerPrice = trigger
+	order.UpdatedAt = time.Now().Unix()
+	return true
+}
+
+// tightens reports whether moving the trigger of stop to trigger brings it
+// closer to the market: up for a sell stop, down for a buy stop.
+func tightens(stop models.Order, trigger float64) bool {
+	if stop.Side == "buy" {
+		return trigger < stop.TriggerPrice
+	}
+	return trigger > stop.TriggerPrice
+}
+
+// breached reports whether price has reached the trigger of stop. Sell stops
+// protect long positions and trigger at or below their trigger price; buy
+// stops trigger at or above it.
+func breached(stop models.Order, price float64) bool {
+	if stop.TriggerPrice <= 0 {
+		return false
+	}
+	if stop.Side == "buy" {
+		return price >= stop.TriggerPrice
+	}
+	return price <= stop.TriggerPrice
+}
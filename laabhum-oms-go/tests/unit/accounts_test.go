@@ -0,0 +1,254 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/api"
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestOrdersTradesAndPositionsAreScopedToTheirAccount(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(),
+		service.WithRiskChecks(service.MaxPositionCheck{Max: 10}))
+
+	mine, err := svc.CreateOrder(models.Order{AccountID: "A1", UserID: "u1", Symbol: "INFY", Side: "buy", Quantity: 10, Price: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	theirs, err := svc.CreateOrder(models.Order{AccountID: "A2", UserID: "u2", Symbol: "INFY", Side: "buy", Quantity: 10, Price: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, order := range []*models.Order{mine, theirs} {
+		trade, _, err := svc.RecordFill(models.Fill{OrderID: order.ID, ExecutionID: "a-" + order.AccountID, Quantity: 10, Price: 100 + float64(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if trade.AccountID != order.AccountID || trade.UserID != order.UserID {
+			t.Errorf("trade of %s = %+v", order.AccountID, trade)
+		}
+	}
+
+	// Each account holds its own position and is limited by its own.
+	positions := svc.GetPositions("A1", "INFY", "")
+	if len(positions) != 1 || positions[0].AccountID != "A1" || positions[0].Quantity != 10 || positions[0].AvgPrice != 100 {
+		t.Fatalf("A1 positions = %+v", positions)
+	}
+	if all := svc.GetPositions("", "INFY", ""); len(all) != 2 {
+		t.Fatalf("all positions = %+v", all)
+	}
+	var riskErr *service.RiskError
+	if _, err := svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "INFY", Side: "buy", Quantity: 1, Price: 100}); !errors.As(err, &riskErr) || riskErr.Code != service.RiskMaxPosition {
+		t.Fatalf("order over A1's position limit: err = %v", err)
+	}
+	if _, err := svc.ConvertPosition(models.PositionConversion{AccountID: "A2", Symbol: "INFY", Quantity: 4, FromProduct: models.ProductMIS, ToProduct: models.ProductNRML}); err != nil {
+		t.Fatal(err)
+	}
+	if got := svc.GetPositions("A1", "INFY", models.ProductNRML); len(got) != 0 {
+		t.Errorf("A2's conversion moved A1's position: %+v", got)
+	}
+
+	parent, err := svc.CreateScalperOrder(models.ScalperOrder{AccountID: "A2", UserID: "u2", Symbol: "TCS", Quantity: 4, Legs: 2, ParentOrder: models.Order{Side: "buy", Price: 3000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, child := range parent.ChildOrders {
+		if child.AccountID != "A2" || child.UserID != "u2" {
+			t.Errorf("child = %+v", child)
+		}
+	}
+
+	trader := models.Caller{AccountID: "A1", UserID: "u1", Role: models.RoleTrader}
+	if err := svc.Authorize(trader, mine.ID); err != nil {
+		t.Errorf("own order: %v", err)
+	}
+	for _, id := range []string{theirs.ID, parent.ID} {
+		if err := svc.Authorize(trader, id); !errors.Is(err, service.ErrAccessDenied) {
+			t.Errorf("order %s of another account: err = %v", id, err)
+		}
+	}
+	if err := svc.Authorize(models.Caller{Role: models.RoleOperator}, theirs.ID); err != nil {
+		t.Errorf("operator: %v", err)
+	}
+
+	exits, err := svc.ExitAllTrades("A1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exits) != 1 || exits[0].AccountID != "A1" || exits[0].UserID != "u1" {
+		t.Fatalf("exits = %+v", exits)
+	}
+	page, err := svc.QueryOrders(service.OrderQuery{Filter: service.OrderFilter{AccountID: "A2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, order := range page.Orders {
+		if order.AccountID != "A2" || order.Role == models.OrderRoleExit {
+			t.Errorf("A2 order = %+v", order)
+		}
+	}
+}
+
+// testCallerSecret is shared between the tests and the OMS handlers, as the
+// gateway shares it in production.
+var (
+	testCallerSecret = []byte("test-caller-secret")
+	callerSecret     = api.WithCallerSecret(testCallerSecret)
+)
+
+// setCaller sets the caller headers of r to caller, signed at the time at.
+func setCaller(r *http.Request, caller models.Caller, at time.Time) {
+	if caller.AccountID != "" {
+		r.Header.Set(api.HeaderAccountID, caller.AccountID)
+	}
+	if caller.UserID != "" {
+		r.Header.Set(api.HeaderUserID, caller.UserID)
+	}
+	if caller.Role != "" {
+		r.Header.Set(api.HeaderRole, string(caller.Role))
+	}
+	r.Header.Set(api.HeaderCallerTimestamp, strconv.FormatInt(at.Unix(), 10))
+	r.Header.Set(api.HeaderCallerSignature, api.SignCaller(testCallerSecret, caller.AccountID, caller.UserID, string(caller.Role), at.Unix()))
+}
+
+func TestHTTPRequestsAreScopedToTheCaller(t *testing.T) {
+	repo := repository.NewInMemoryOrderRepository()
+	router := api.SetupRoutes(repo, service.NewOMSService(repo), callerSecret)
+	send := func(method, path, body string, caller models.Caller) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		setCaller(r, caller, time.Now())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+	alice := models.Caller{AccountID: "A1", UserID: "alice"}
+	bob := models.Caller{AccountID: "A2", UserID: "bob"}
+	operator := models.Caller{UserID: "ops", Role: models.RoleOperator}
+
+	const body = `{"symbol": "SBIN", "side": "buy", "quantity": 5, "price": 600}`
+	if w := send(http.MethodPost, "/orders", body, models.Caller{}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous create: %d %s", w.Code, w.Body)
+	}
+	if w := send(http.MethodPost, "/orders", `{"account_id": "A2", "symbol": "SBIN", "side": "buy", "quantity": 5, "price": 600}`, alice); w.Code != http.StatusForbidden {
+		t.Fatalf("create for another account: %d %s", w.Code, w.Body)
+	}
+	var order models.Order
+	w := send(http.MethodPost, "/orders", body, alice)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &order); err != nil {
+		t.Fatal(err)
+	}
+	if order.AccountID != "A1" || order.UserID != "alice" {
+		t.Fatalf("order owned by %s/%s", order.AccountID, order.UserID)
+	}
+	var bobOrder models.Order
+	if w := send(http.MethodPost, "/orders", body, bob); w.Code != http.StatusCreated || json.Unmarshal(w.Body.Bytes(), &bobOrder) != nil {
+		t.Fatalf("create as bob: %d %s", w.Code, w.Body)
+	}
+
+	list := func(caller models.Caller, query string) []models.Order {
+		t.Helper()
+		w := send(http.MethodGet, "/orders"+query, "", caller)
+		var orders []models.Order
+		if err := json.Unmarshal(w.Body.Bytes(), &orders); err != nil {
+			t.Fatalf("list: %d %s", w.Code, w.Body)
+		}
+		return orders
+	}
+	if orders := list(bob, "?account_id=A1"); len(orders) != 1 || orders[0].AccountID != "A2" {
+		t.Errorf("bob sees %+v", orders)
+	}
+	if orders := list(operator, ""); len(orders) != 2 {
+		t.Errorf("operator sees %d orders", len(orders))
+	}
+	if orders := list(operator, "?account_id=A1"); len(orders) != 1 || orders[0].ID != order.ID {
+		t.Errorf("operator sees %+v in A1", orders)
+	}
+
+	// Only operators book fills, and the actor is theirs.
+	fills := "/orders/" + order.ID + "/fills"
+	if w := send(http.MethodPost, fills, `{"execution_id": "x-1", "quantity": 5, "price": 1}`, alice); w.Code != http.StatusForbidden {
+		t.Fatalf("trader booked a fill on its own order: %d %s", w.Code, w.Body)
+	}
+	var trade models.Trade
+	w = send(http.MethodPost, "/orders/"+bobOrder.ID+"/fills", `{"execution_id": "x-2", "quantity": 5, "price": 600, "actor": "bob"}`, operator)
+	if w.Code != http.StatusCreated || json.Unmarshal(w.Body.Bytes(), &trade) != nil || trade.Actor != "ops" {
+		t.Fatalf("operator's fill: %d %s", w.Code, w.Body)
+	}
+
+	cancel := "/oms/scalper/order/" + order.ID + "/" + order.ID + "/cancel"
+	if w := send(http.MethodPost, cancel, "", bob); w.Code != http.StatusForbidden {
+		t.Fatalf("bob canceled alice's order: %d %s", w.Code, w.Body)
+	}
+	if w := send(http.MethodPost, cancel, "", alice); w.Code != http.StatusOK {
+		t.Fatalf("alice's cancel: %d %s", w.Code, w.Body)
+	}
+
+	// Only operators engage and release kill switches, as themselves.
+	for _, action := range []string{"engage", "release"} {
+		if w := send(http.MethodPost, "/oms/admin/kill-switch/"+action, `{"account_id": "A2"}`, bob); w.Code != http.StatusForbidden {
+			t.Fatalf("bob's %s of his own kill switch: %d %s", action, w.Code, w.Body)
+		}
+	}
+	if w := send(http.MethodPost, "/oms/admin/kill-switch/engage", `{"account_id": "A2", "actor": "risk-desk"}`, operator); w.Code != http.StatusOK ||
+		!strings.Contains(w.Body.String(), `"account_id":"A2"`) || !strings.Contains(w.Body.String(), `"actor":"ops"`) {
+		t.Fatalf("operator's kill switch: %d %s", w.Code, w.Body)
+	}
+	if w := send(http.MethodPost, "/oms/admin/kill-switch/release", `{"account_id": "A2"}`, bob); w.Code != http.StatusForbidden {
+		t.Fatalf("bob released the kill switch risk engaged: %d %s", w.Code, w.Body)
+	}
+	if w := send(http.MethodPost, "/oms/positions/sync", "", alice); w.Code != http.StatusForbidden {
+		t.Fatalf("trader synced positions: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestUnsignedCallerHeadersAreRefused(t *testing.T) {
+	repo := repository.NewInMemoryOrderRepository()
+	router := api.SetupRoutes(repo, service.NewOMSService(repo), callerSecret)
+	operator := models.Caller{UserID: "ops", Role: models.RoleOperator}
+	get := func(sign func(r *http.Request)) int {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		sign(r)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := get(func(r *http.Request) { setCaller(r, operator, time.Now()) }); code != http.StatusOK {
+		t.Fatalf("signed operator: %d", code)
+	}
+	for name, sign := range map[string]func(r *http.Request){
+		"unsigned": func(r *http.Request) {
+			r.Header.Set(api.HeaderUserID, "ops")
+			r.Header.Set(api.HeaderRole, string(models.RoleOperator))
+		},
+		"promoted": func(r *http.Request) {
+			setCaller(r, models.Caller{AccountID: "A1", UserID: "alice"}, time.Now())
+			r.Header.Set(api.HeaderRole, string(models.RoleOperator))
+		},
+		"stale": func(r *http.Request) { setCaller(r, operator, time.Now().Add(-time.Hour)) },
+	} {
+		if code := get(sign); code != http.StatusUnauthorized {
+			t.Errorf("%s operator: %d, want 401", name, code)
+		}
+	}
+
+	// Without a secret no caller is trusted.
+	router = api.SetupRoutes(repo, service.NewOMSService(repo))
+	if code := get(func(r *http.Request) { setCaller(r, operator, time.Now()) }); code != http.StatusUnauthorized {
+		t.Errorf("operator without a caller secret: %d, want 401", code)
+	}
+}
@@ -0,0 +1,158 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestAmendOrderIsVersioned(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+	order, err := svc.CreateOrder(models.Order{Symbol: "WIPRO", Side: "buy", Quantity: 10, Price: 450})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Version != 1 {
+		t.Fatalf("new order version = %d, want 1", order.Version)
+	}
+
+	quantity, price := 12, 449.5
+	amended, err := svc.AmendOrder(order.ID, "", models.AmendRequest{Version: 1, Quantity: &quantity, Price: &price, Actor: "trader-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amended.Version != 2 || amended.Quantity != 12 || amended.Price != 449.5 {
+		t.Fatalf("amended = %+v", amended)
+	}
+	if len(amended.Amendments) != 1 || amended.Amendments[0].Version != 2 || amended.Amendments[0].Actor != "trader-1" || len(amended.Amendments[0].Changes) != 2 {
+		t.Fatalf("amendments = %+v", amended.Amendments)
+	}
+
+	// A second trader working from version 1 is turned away.
+	price = 455
+	if _, err := svc.AmendOrder(order.ID, "", models.AmendRequest{Version: 1, Price: &price, Actor: "trader-2"}); !errors.Is(err, service.ErrVersionConflict) {
+		t.Fatalf("stale amendment: err = %v", err)
+	}
+	if _, err := svc.AmendOrder(order.ID, "", models.AmendRequest{Price: &price}); err == nil {
+		t.Fatal("amendment without a version accepted")
+	}
+
+	// Fills change the order too, so they move the version on.
+	if _, _, err := svc.RecordFill(models.Fill{OrderID: order.ID, ExecutionID: "a-1", Quantity: 5, Price: 449.5}); err != nil {
+		t.Fatal(err)
+	}
+	quantity = 5
+	if _, err := svc.AmendOrder(order.ID, "", models.AmendRequest{Version: 2, Quantity: &quantity}); !errors.Is(err, service.ErrVersionConflict) {
+		t.Fatalf("amendment against the pre-fill version: err = %v", err)
+	}
+	if _, err := svc.AmendOrder(order.ID, "", models.AmendRequest{Version: 3, Quantity: &quantity}); err == nil {
+		t.Fatal("quantity amended down to the filled quantity")
+	}
+
+	// Scalper children are versioned on their own, and the parent with them.
+	parent, err := svc.CreateScalperOrder(models.ScalperOrder{Symbol: "WIPRO", Quantity: 10, Legs: 2, ParentOrder: models.Order{Side: "buy", Price: 450}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := parent.ChildOrders[0]
+	price = 451
+	if _, err := svc.AmendOrder(parent.ID, child.ID, models.AmendRequest{Version: child.Version, Price: &price}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.AmendOrder(parent.ID, child.ID, models.AmendRequest{Version: child.Version, Price: &price}); !errors.Is(err, service.ErrVersionConflict) {
+		t.Fatalf("second amendment of child version %d: err = %v", child.Version, err)
+	}
+	stored, err := svc.GetScalperOrder(parent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Version != parent.Version+1 {
+		t.Fatalf("parent version = %d, want %d", stored.Version, parent.Version+1)
+	}
+}
+
+func TestAmendingAChildResizesTheScalperOrder(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+	for _, c := range []struct {
+		name     string
+		quantity int
+	}{
+		{"shrink", 3},
+		{"grow", 8},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			parent, err := svc.CreateScalperOrder(models.ScalperOrder{Symbol: "WIPRO", Quantity: 10, Legs: 2, ParentOrder: models.Order{Side: "buy", Price: 450}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			first, second := parent.ChildOrders[0], parent.ChildOrders[1]
+			quantity := c.quantity
+			if _, err := svc.AmendOrder(parent.ID, second.ID, models.AmendRequest{Version: second.Version, Quantity: &quantity}); err != nil {
+				t.Fatal(err)
+			}
+			stored, err := svc.GetScalperOrder(parent.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := first.Quantity + c.quantity; stored.Quantity != want || stored.ParentOrder.Quantity != want {
+				t.Fatalf("parent quantity = %d/%d, want %d", stored.Quantity, stored.ParentOrder.Quantity, want)
+			}
+
+			status := func(child models.Order, executionID string, quantity int) string {
+				t.Helper()
+				if _, _, err := svc.RecordFill(models.Fill{OrderID: child.ID, ExecutionID: c.name + executionID, Quantity: quantity, Price: 450}); err != nil {
+					t.Fatal(err)
+				}
+				stored, err := svc.GetScalperOrder(parent.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return stored.Status
+			}
+			if got := status(first, "-1", first.Quantity); got != models.ScalperStatusPartiallyExecuted {
+				t.Errorf("after the first child filled: %s", got)
+			}
+			if got := status(second, "-2", c.quantity); got != models.ScalperStatusFullyExecuted {
+				t.Errorf("after the amended child filled: %s", got)
+			}
+		})
+	}
+}
+
+func TestAmendmentsPassTheRiskChecks(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(),
+		service.WithRiskChecks(service.MaxQuantityCheck{Max: 10}))
+	order, err := svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "WIPRO", Side: "buy", Quantity: 10, Price: 450})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quantity := 11
+	_, err = svc.AmendOrder(order.ID, "", models.AmendRequest{Version: order.Version, Quantity: &quantity})
+	var risk *service.RiskError
+	if !errors.As(err, &risk) || risk.Code != service.RiskMaxQuantity {
+		t.Fatalf("oversized amendment: err = %v", err)
+	}
+
+	// The last traded price is 450, so 460 is outside the band; a stop loss
+	// is not checked.
+	svc = service.NewOMSService(repository.NewInMemoryOrderRepository(),
+		service.WithRiskChecks(service.PriceBandCheck{Percent: 1}))
+	svc.Prices().Update("WIPRO", 450)
+	order, err = svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "WIPRO", Side: "buy", Quantity: 10, Price: 450})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stopLoss := 300.0
+	if order, err = svc.AmendOrder(order.ID, "", models.AmendRequest{Version: order.Version, StopLoss: &stopLoss}); err != nil {
+		t.Fatal(err)
+	}
+	price := 460.0
+	_, err = svc.AmendOrder(order.ID, "", models.AmendRequest{Version: order.Version, Price: &price})
+	if !errors.As(err, &risk) || risk.Code != service.RiskPriceBand {
+		t.Fatalf("amendment outside the price band: err = %v", err)
+	}
+}
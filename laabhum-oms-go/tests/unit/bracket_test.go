@@ -0,0 +1,118 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+// bracketLegs returns the entry, target and stop-loss of a bracket order.
+func bracketLegs(t *testing.T, svc *service.OMSService, parentID string) (entry, target, stop models.Order) {
+	t.Helper()
+	order, err := svc.GetScalperOrder(parentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, child := range order.ChildOrders {
+		switch child.Role {
+		case models.OrderRoleEntry:
+			entry = child
+		case models.OrderRoleTarget:
+			target = child
+		case models.OrderRoleStopLoss:
+			stop = child
+		}
+	}
+	if entry.ID == "" || target.ID == "" || stop.ID == "" {
+		t.Fatalf("bracket children = %+v", order.ChildOrders)
+	}
+	return entry, target, stop
+}
+
+func TestBracketLegsAreOneCancelsOther(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+
+	parent, err := svc.CreateScalperOrder(models.ScalperOrder{
+		Symbol:      "RELIANCE",
+		Quantity:    10,
+		ParentOrder: models.Order{Side: "buy", Price: 100},
+		Bracket:     &models.Bracket{TargetPrice: 110, StopLossPrice: 95},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, target, stop := bracketLegs(t, svc, parent.ID)
+	if target.Status != models.OrderStatusNew || stop.Status != models.OrderStatusNew || target.OCOGroupID != stop.OCOGroupID {
+		t.Fatalf("legs before the entry fills: target=%+v stop=%+v", target, stop)
+	}
+
+	if _, err := svc.ModifyScalperQuantity(parent.ID, 8, parent.Version); err != nil {
+		t.Fatal(err)
+	}
+	if _, target, stop = bracketLegs(t, svc, parent.ID); target.Quantity != 8 || stop.Quantity != 8 {
+		t.Fatalf("legs after resize: target=%d stop=%d, want 8", target.Quantity, stop.Quantity)
+	}
+
+	fill := func(orderID, execution string, quantity int, price float64) {
+		t.Helper()
+		if _, _, err := svc.RecordFill(models.Fill{OrderID: orderID, ExecutionID: execution, Quantity: quantity, Price: price}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fill(entry.ID, "e-1", 5, 100)
+	_, target, stop = bracketLegs(t, svc, parent.ID)
+	if target.Status != models.OrderStatusPending || target.Quantity != 5 || stop.Quantity != 5 {
+		t.Fatalf("legs after partial entry: target=%+v stop=%+v", target, stop)
+	}
+
+	// A partial target fill shrinks the stop to what is still open.
+	fill(target.ID, "e-2", 2, 110)
+	fill(entry.ID, "e-3", 3, 100)
+	_, target, stop = bracketLegs(t, svc, parent.ID)
+	if target.Quantity != 8 || stop.Quantity != 6 {
+		t.Fatalf("legs after target partial: target=%d stop=%d, want 8 and 6", target.Quantity, stop.Quantity)
+	}
+
+	// The stop closing the rest cancels the target.
+	fill(stop.ID, "e-4", 6, 95)
+	_, target, _ = bracketLegs(t, svc, parent.ID)
+	if target.Status != models.OrderStatusCanceled {
+		t.Errorf("target after stop filled = %q, want canceled", target.Status)
+	}
+	assertScalperStatus(t, svc, parent.ID, models.ScalperStatusFullyExecuted)
+}
+
+func TestBracketLegsCanceledWithUnfilledEntry(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+
+	if _, err := svc.CreateScalperOrder(models.ScalperOrder{
+		Symbol:      "RELIANCE",
+		Quantity:    10,
+		ParentOrder: models.Order{Side: "buy", Price: 100},
+		Bracket:     &models.Bracket{TargetPrice: 90, StopLossPrice: 95},
+	}); err == nil {
+		t.Fatal("a buy bracket with the target below the entry should be rejected")
+	}
+
+	parent, err := svc.CreateScalperOrder(models.ScalperOrder{
+		Symbol:      "RELIANCE",
+		Quantity:    10,
+		ParentOrder: models.Order{Side: "sell", Price: 100},
+		Bracket:     &models.Bracket{TargetPrice: 90, StopLossPrice: 105},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _, _ := bracketLegs(t, svc, parent.ID)
+	if err := svc.CancelOrder(parent.ID, entry.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	_, target, stop := bracketLegs(t, svc, parent.ID)
+	if target.Status != models.OrderStatusCanceled || stop.Status != models.OrderStatusCanceled {
+		t.Errorf("legs after entry canceled: target=%q stop=%q", target.Status, stop.Status)
+	}
+	assertScalperStatus(t, svc, parent.ID, models.ScalperStatusCanceled)
+}
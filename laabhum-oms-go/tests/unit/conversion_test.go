@@ -0,0 +1,112 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+type fakeBroker struct {
+	conversions []models.PositionConversion
+	err         error
+	during      func() // Runs while the broker handles a conversion
+}
+
+func (b *fakeBroker) ConvertPosition(conv models.PositionConversion) error {
+	if b.during != nil {
+		b.during()
+	}
+	if b.err != nil {
+		return b.err
+	}
+	b.conversions = append(b.conversions, conv)
+	return nil
+}
+
+func TestConvertPositionMovesQuantityBetweenProducts(t *testing.T) {
+	broker := &fakeBroker{}
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(), service.WithBrokerAdapter(broker))
+
+	order, err := svc.CreateOrder(models.Order{Symbol: "SBIN", Side: "buy", Quantity: 10, Price: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Product != models.ProductMIS {
+		t.Fatalf("default product = %q, want MIS", order.Product)
+	}
+	if _, _, err := svc.RecordFill(models.Fill{OrderID: order.ID, ExecutionID: "e-1", Quantity: 10, Price: 600}); err != nil {
+		t.Fatal(err)
+	}
+
+	conv := models.PositionConversion{Symbol: "SBIN", Quantity: 11, FromProduct: models.ProductMIS, ToProduct: models.ProductNRML}
+	if _, err := svc.ConvertPosition(conv); err == nil {
+		t.Fatal("converting more than the open quantity should fail")
+	}
+
+	broker.err = errors.New("outside conversion window")
+	conv.Quantity = 6
+	if _, err := svc.ConvertPosition(conv); err == nil {
+		t.Fatal("a broker rejection should fail the conversion")
+	}
+	if got := svc.GetPositions("", "SBIN", models.ProductNRML); len(got) != 0 {
+		t.Fatalf("rejected conversion was booked: %+v", got)
+	}
+
+	broker.err = nil
+	if _, err := svc.ConvertPosition(conv); err != nil {
+		t.Fatal(err)
+	}
+	if len(broker.conversions) != 1 || broker.conversions[0].Side != "buy" {
+		t.Errorf("forwarded conversions = %+v", broker.conversions)
+	}
+
+	positions := svc.GetPositions("", "SBIN", "")
+	if len(positions) != 2 {
+		t.Fatalf("positions = %+v", positions)
+	}
+	mis, nrml := positions[0], positions[1]
+	if mis.ID != "SBIN:MIS" || mis.Quantity != 4 || nrml.ID != "SBIN:NRML" || nrml.Quantity != 6 || nrml.AvgPrice != 600 {
+		t.Errorf("positions after conversion = %+v", positions)
+	}
+	if mis.RealizedPnL != 0 || nrml.BuyQuantity != 0 {
+		t.Errorf("conversion must not realize P&L or count as a buy: %+v", positions)
+	}
+
+	// The conversion trades are stored, so a rebuild gives the same book.
+	if err := svc.SyncPositions(); err != nil {
+		t.Fatal(err)
+	}
+	if got := svc.GetPositions("", "SBIN", models.ProductNRML); len(got) != 1 || got[0].Quantity != 6 {
+		t.Errorf("rebuilt NRML position = %+v", got)
+	}
+}
+
+func TestConvertPositionRechecksThePositionAfterTheBroker(t *testing.T) {
+	broker := &fakeBroker{}
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(), service.WithBrokerAdapter(broker))
+	fill := func(side, executionID string) {
+		t.Helper()
+		order, err := svc.CreateOrder(models.Order{Symbol: "SBIN", Side: side, Quantity: 10, Price: 600})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := svc.RecordFill(models.Fill{OrderID: order.ID, ExecutionID: executionID, Quantity: 10, Price: 600}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fill("buy", "e-1")
+
+	// The position is closed while the broker converts it; the service is
+	// not locked meanwhile.
+	broker.during = func() { fill("sell", "e-2") }
+	_, err := svc.ConvertPosition(models.PositionConversion{Symbol: "SBIN", Quantity: 10, FromProduct: models.ProductMIS, ToProduct: models.ProductNRML})
+	if service.Code(err) != service.CodeConflict {
+		t.Fatalf("err = %v, want a conflict", err)
+	}
+	if got := svc.GetPositions("", "SBIN", models.ProductNRML); len(got) != 0 {
+		t.Errorf("conversion of a closed position was booked: %+v", got)
+	}
+}
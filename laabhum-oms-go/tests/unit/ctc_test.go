@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestCTCMovesStopLossToCostOnceInProfit(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(),
+		service.WithCTCCosts(service.CTCCosts{PerUnit: 0.5, Bps: 10}))
+
+	parent, err := svc.CreateScalperOrder(models.ScalperOrder{
+		Symbol:      "NIFTY",
+		Quantity:    20,
+		Legs:        2,
+		ParentOrder: models.Order{Side: "buy", Price: 100, StopLoss: 95},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := parent.ChildOrders[0], parent.ChildOrders[1]
+	if _, _, err := svc.RecordFill(models.Fill{OrderID: first.ID, ExecutionID: "e-1", Quantity: 10, Price: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cost is 100 + 0.5 + 0.1; the fill itself set the ltp to 100.
+	if _, err := svc.CTCChildOrder(parent.ID, first.ID, 0); !errors.Is(err, service.ErrNotInProfit) {
+		t.Fatalf("CTC at entry price: err = %v, want ErrNotInProfit", err)
+	}
+
+	svc.Prices().Update("NIFTY", 101)
+	updated, err := svc.CTCOrder(parent.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := updated.ChildOrders[0].StopLoss; math.Abs(got-100.6) > 1e-9 {
+		t.Errorf("stop loss = %v, want 100.6", got)
+	}
+	// The unfilled child keeps the stop it was created with.
+	if got := updated.ChildOrders[1].StopLoss; got != 95 {
+		t.Errorf("unfilled child %s stop loss = %v, want 95", second.ID, got)
+	}
+}
+
+func TestOnPriceExitsAChildAtItsStopLoss(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+	parent, err := svc.CreateScalperOrder(models.ScalperOrder{
+		Symbol:      "NIFTY",
+		Quantity:    20,
+		Legs:        2,
+		ParentOrder: models.Order{Side: "buy", Price: 100, StopLoss: 95},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := parent.ChildOrders[0]
+	if _, _, err := svc.RecordFill(models.Fill{OrderID: first.ID, ExecutionID: "e-1", Quantity: 10, Price: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CTCChildOrder(parent.ID, first.ID, 102); err != nil {
+		t.Fatal(err)
+	}
+
+	// Above the stop-loss at cost nothing happens; at it, the child is exited.
+	if exits, err := svc.OnPrice("NIFTY", 101); err != nil || len(exits) != 0 {
+		t.Fatalf("OnPrice(101) = %v, %v", exits, err)
+	}
+	exits, err := svc.OnPrice("NIFTY", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exits) != 1 || exits[0].Role != models.OrderRoleExit || exits[0].LinkedOrderID != first.ID ||
+		exits[0].Side != "sell" || exits[0].Quantity != 10 || exits[0].OrderType != models.OrderTypeMarket {
+		t.Fatalf("exits = %+v", exits)
+	}
+
+	// The pending exit covers the child; the unfilled child has no position.
+	if exits, err := svc.OnPrice("NIFTY", 90); err != nil || len(exits) != 0 {
+		t.Errorf("OnPrice(90) = %+v, %v", exits, err)
+	}
+}
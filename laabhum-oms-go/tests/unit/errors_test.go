@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/api"
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestServiceErrorsHaveACode(t *testing.T) {
+	repos := map[string]repository.OrderRepository{
+		"memory": repository.NewInMemoryOrderRepository(),
+		"sql":    openSQLiteRepository(t),
+	}
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewOMSService(repo, service.WithRiskChecks(service.MaxQuantityCheck{Max: 100}))
+			order, err := svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "INFY", Side: "buy", Quantity: 10, Price: 1500})
+			if err != nil {
+				t.Fatal(err)
+			}
+			quantity := 20
+			if _, err := svc.AmendOrder("", order.ID, models.AmendRequest{Version: order.Version, Quantity: &quantity}); err != nil {
+				t.Fatal(err)
+			}
+			if err := svc.CancelOrder("", order.ID, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			_, missing := svc.GetOrder("missing")
+			_, invalid := svc.CreateOrder(models.Order{Symbol: "INFY", Side: "hold", Quantity: 1, Price: 1})
+			_, risky := svc.CreateOrder(models.Order{Symbol: "INFY", Side: "buy", Quantity: 1000, Price: 1})
+			_, stale := svc.AmendOrder("", order.ID, models.AmendRequest{Version: order.Version, Quantity: &quantity})
+			for _, c := range []struct {
+				err  error
+				code service.ErrorCode
+			}{
+				{missing, service.CodeNotFound},
+				{invalid, service.CodeValidationFailed},
+				{risky, service.CodeRiskRejected},
+				{stale, service.CodeConflict},
+				{svc.CancelOrder("", order.ID, ""), service.CodeInvalidTransition},
+				{svc.CancelOrder("", "missing", ""), service.CodeNotFound},
+				{svc.Authorize(models.Caller{AccountID: "A2"}, order.ID), service.CodeAccessDenied},
+				{errors.New("disk full"), service.CodeInternal},
+			} {
+				if got := service.Code(c.err); got != c.code {
+					t.Errorf("Code(%v) = %s, want %s", c.err, got, c.code)
+				}
+			}
+			if !errors.Is(missing, repository.ErrOrderNotFound) || !errors.Is(stale, service.ErrVersionConflict) {
+				t.Errorf("sentinels lost: %v, %v", missing, stale)
+			}
+			if invalid.Error() != "side must be 'buy' or 'sell'" {
+				t.Errorf("validation message = %q", invalid)
+			}
+		})
+	}
+}
+
+func TestHTTPErrorsCarryACodeAndStatus(t *testing.T) {
+	repo := repository.NewInMemoryOrderRepository()
+	svc := service.NewOMSService(repo, service.WithRiskChecks(service.MaxQuantityCheck{Max: 100}))
+	router := api.SetupRoutes(repo, svc, callerSecret)
+	order, err := svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "ITC", Side: "buy", Quantity: 1, Price: 400})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel := "/oms/scalper/order/" + order.ID + "/" + order.ID + "/cancel"
+	modify := "/oms/scalper/order/" + order.ID + "/" + order.ID + "/modify"
+
+	for _, c := range []struct {
+		name, method, path, account, body string
+		status                            int
+		code                              service.ErrorCode
+	}{
+		{"no caller", http.MethodGet, "/orders", "", "", http.StatusUnauthorized, api.CodeUnauthorized},
+		{"bad json", http.MethodPost, "/orders", "A1", "{", http.StatusBadRequest, service.CodeValidationFailed},
+		{"bad order", http.MethodPost, "/orders", "A1", `{"symbol": "ITC", "side": "hold", "quantity": 1, "price": 400}`, http.StatusBadRequest, service.CodeValidationFailed},
+		{"risk", http.MethodPost, "/orders", "A1", `{"symbol": "ITC", "side": "buy", "quantity": 500, "price": 400}`, http.StatusUnprocessableEntity, service.CodeRiskRejected},
+		{"missing", http.MethodGet, "/orders/missing", "A1", "", http.StatusNotFound, service.CodeNotFound},
+		{"other account", http.MethodGet, "/orders/" + order.ID, "A2", "", http.StatusForbidden, service.CodeAccessDenied},
+		{"stale version", http.MethodPatch, modify, "A1", `{"version": 99, "quantity": 2}`, http.StatusConflict, service.CodeConflict},
+		{"cancel", http.MethodPost, cancel, "A1", "", http.StatusOK, ""},
+		{"cancel again", http.MethodPost, cancel, "A1", "", http.StatusConflict, service.CodeInvalidTransition},
+	} {
+		r := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+		if c.account != "" {
+			setCaller(r, models.Caller{AccountID: c.account}, time.Now())
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Code != c.status {
+			t.Errorf("%s: status %d, want %d: %s", c.name, w.Code, c.status, w.Body)
+			continue
+		}
+		if c.code == "" {
+			continue
+		}
+		var resp api.ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Code != c.code || resp.Message == "" {
+			t.Errorf("%s: body %s, want code %s", c.name, w.Body, c.code)
+		}
+		if c.code == service.CodeRiskRejected && (resp.Risk == nil || resp.Risk.Code != service.RiskMaxQuantity || resp.Risk.OrderID == "") {
+			t.Errorf("%s: risk = %+v", c.name, resp.Risk)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/pkg/kafka"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestExecutionReportsDriveOrderStatus(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+	order, err := svc.CreateOrder(models.Order{Symbol: "TCS", Side: "buy", Quantity: 10, Price: 3500})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apply := func(report models.ExecutionReport, wantApplied bool, wantStatus models.OrderStatus) {
+		t.Helper()
+		got, applied, err := svc.ApplyExecutionReport(report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if applied != wantApplied || got.Status != wantStatus {
+			t.Fatalf("%s report: applied = %v, status = %s; want %v, %s", report.Status, applied, got.Status, wantApplied, wantStatus)
+		}
+	}
+
+	// The first report links the broker order id to the OMS order.
+	accepted := models.ExecutionReport{BrokerOrderID: "B-1", OrderID: order.ID, Status: models.ExecutionAccepted, Timestamp: 100}
+	apply(accepted, true, models.OrderStatusOpen)
+	apply(accepted, false, models.OrderStatusOpen)
+
+	// A cancellation that happened before the last report is stale.
+	apply(models.ExecutionReport{BrokerOrderID: "B-1", Status: models.ExecutionCanceled, Timestamp: 50}, false, models.OrderStatusOpen)
+
+	apply(models.ExecutionReport{BrokerOrderID: "B-1", Status: models.ExecutionFilled, Price: 3498, Timestamp: 200}, true, models.OrderStatusFilled)
+	apply(models.ExecutionReport{BrokerOrderID: "B-1", Status: models.ExecutionFilled, Price: 3498, Timestamp: 200}, false, models.OrderStatusFilled)
+	apply(models.ExecutionReport{BrokerOrderID: "B-1", Status: models.ExecutionCanceled}, false, models.OrderStatusFilled)
+
+	trades, err := svc.GetTrades("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 1 || trades[0].Quantity != 10 || trades[0].Price != 3498 {
+		t.Fatalf("trades = %+v", trades)
+	}
+
+	if _, _, err := svc.ApplyExecutionReport(models.ExecutionReport{BrokerOrderID: "B-404", Status: models.ExecutionFilled}); !errors.Is(err, service.ErrUnknownBrokerOrder) {
+		t.Fatalf("unknown broker order: err = %v", err)
+	}
+}
+
+func TestParseAdapterOrderEvent(t *testing.T) {
+	report, err := kafka.ParseExecutionReport([]byte(`{
+		"type": "ORDER_UPDATED",
+		"order": {"id": "o-1", "price": 101.5, "status": "PENDING", "last_modified": "2024-05-02T09:15:00Z"},
+		"response": {"order_id": "o-1", "broker_order_id": "B-7", "status": "COMPLETED"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.BrokerOrderID != "B-7" || report.OrderID != "o-1" || report.Status != models.ExecutionFilled || report.Price != 101.5 || report.Timestamp == 0 {
+		t.Errorf("report = %+v", report)
+	}
+
+	report, err = kafka.ParseExecutionReport([]byte(`{"type": "ORDER_CANCELLED", "order": {"status": "PENDING"}, "response": {"broker_order_id": "B-7"}}`))
+	if err != nil || report.Status != models.ExecutionCanceled {
+		t.Errorf("cancellation = %+v, %v", report, err)
+	}
+}
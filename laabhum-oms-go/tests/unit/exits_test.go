@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestExitScalperTradesFlattensNetQuantity(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+
+	parent, err := svc.CreateScalperOrder(models.ScalperOrder{
+		Symbol:      "BANKNIFTY",
+		Quantity:    20,
+		Legs:        2,
+		ParentOrder: models.Order{Side: "buy", Price: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := parent.ChildOrders[0], parent.ChildOrders[1]
+	if _, _, err := svc.RecordFill(models.Fill{OrderID: first.ID, ExecutionID: "e-1", Quantity: 10, Price: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.RecordFill(models.Fill{OrderID: second.ID, ExecutionID: "e-2", Quantity: 4, Price: 101}); err != nil {
+		t.Fatal(err)
+	}
+
+	exits, err := svc.ExitChildTrade(parent.ID, second.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exits) != 1 || exits[0].Quantity != 4 || exits[0].Side != "sell" || exits[0].LinkedOrderID != second.ID {
+		t.Fatalf("child exit = %+v", exits)
+	}
+
+	// Flattening the whole parent replaces the still resting exit of the
+	// second child and exits the first one.
+	exits, err = svc.ExitScalperTrades(parent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, exit := range exits {
+		total += exit.Quantity
+	}
+	if len(exits) != 2 || total != 14 {
+		t.Fatalf("exits = %+v, want 2 orders for 14", exits)
+	}
+
+	updated, err := svc.GetScalperOrder(parent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canceledExits, working := 0, 0
+	for _, child := range updated.ChildOrders {
+		switch {
+		case child.ID == second.ID && child.Status != models.OrderStatusCanceled:
+			t.Errorf("unfilled part of the second child is %q, want canceled", child.Status)
+		case child.Role == models.OrderRoleExit && child.Status == models.OrderStatusCanceled:
+			canceledExits++
+		case child.Role == models.OrderRoleExit:
+			working++
+		}
+	}
+	if canceledExits != 1 || working != 2 {
+		t.Errorf("canceled exits = %d, working exits = %d", canceledExits, working)
+	}
+	assertScalperStatus(t, svc, parent.ID, models.ScalperStatusPartiallyExecuted)
+
+	// Once the exits fill there is nothing left to flatten.
+	if err := svc.ExecuteAllChildOrders(parent.ID); err != nil {
+		t.Fatal(err)
+	}
+	if exits, err := svc.ExitAllTrades(""); err != nil || len(exits) != 0 {
+		t.Errorf("ExitAllTrades after flattening: exits=%v err=%v", exits, err)
+	}
+}
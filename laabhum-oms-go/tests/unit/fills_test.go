@@ -0,0 +1,50 @@
+package unit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestRecordFillAggregatesAndIsIdempotent(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+
+	order, err := svc.CreateOrder(models.Order{Symbol: "AAPL", Side: "buy", Quantity: 10, Price: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, recorded, err := svc.RecordFill(models.Fill{OrderID: order.ID, ExecutionID: "e-1", Quantity: 4, Price: 100}); err != nil || !recorded {
+		t.Fatalf("first fill: recorded=%v err=%v", recorded, err)
+	}
+	// A replayed execution must not be counted twice.
+	if _, recorded, err := svc.RecordFill(models.Fill{OrderID: order.ID, ExecutionID: "e-1", Quantity: 4, Price: 100}); err != nil || recorded {
+		t.Fatalf("replayed fill: recorded=%v err=%v", recorded, err)
+	}
+	if _, _, err := svc.RecordFill(models.Fill{OrderID: order.ID, ExecutionID: "e-2", Quantity: 6, Price: 105}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.RecordFill(models.Fill{OrderID: order.ID, ExecutionID: "e-3", Quantity: 1, Price: 105}); err == nil {
+		t.Error("overfilling the order should fail")
+	}
+
+	orders, _ := svc.GetOrders()
+	got := orders[0]
+	if got.FilledQuantity != 10 || got.Status != models.OrderStatusFilled {
+		t.Errorf("filled=%d status=%q", got.FilledQuantity, got.Status)
+	}
+	if math.Abs(got.AvgFillPrice-103) > 1e-9 {
+		t.Errorf("avg fill price = %v, want 103", got.AvgFillPrice)
+	}
+
+	trades, err := svc.GetTrades(order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 2 {
+		t.Errorf("len(trades) = %d, want 2", len(trades))
+	}
+}
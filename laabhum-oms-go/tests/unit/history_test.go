@@ -0,0 +1,165 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/api"
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+// historyOf returns the kind, event and actor of every entry of the history of
+// order id.
+func historyOf(t *testing.T, svc *service.OMSService, id string) []string {
+	t.Helper()
+	history, err := svc.OrderHistory(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []string
+	for _, entry := range history.Entries {
+		entries = append(entries, string(entry.Kind)+" "+string(entry.Event)+" "+entry.Actor)
+	}
+	return entries
+}
+
+func assertHistory(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("history:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestOrderHistoryRecordsWhoChangedTheOrder(t *testing.T) {
+	repos := map[string]repository.OrderRepository{
+		"memory": repository.NewInMemoryOrderRepository(),
+		"sql":    openSQLiteRepository(t),
+	}
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewOMSService(repo)
+
+			// Amended by a trader, then canceled by another.
+			order, err := svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "INFY", Side: "buy", Quantity: 10, Price: 1500})
+			if err != nil {
+				t.Fatal(err)
+			}
+			quantity := 5
+			if _, err := svc.AmendOrder("", order.ID, models.AmendRequest{Version: order.Version, Quantity: &quantity, Actor: "alice"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := svc.CancelOrder("", order.ID, "bob"); err != nil {
+				t.Fatal(err)
+			}
+			history, err := svc.OrderHistory(order.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			last := history.Entries[len(history.Entries)-1]
+			if last.Transition == nil || last.Transition.To != models.OrderStatusCanceled {
+				t.Errorf("last entry = %+v", last)
+			}
+			for _, entry := range history.Entries {
+				if entry.Kind == models.HistoryAmendment && entry.Amendment.Changes[0].Field != "quantity" {
+					t.Errorf("amendment = %+v", entry.Amendment)
+				}
+			}
+			assertHistory(t, historyOf(t, svc, order.ID),
+				"status order.created ",
+				"amendment order.modified alice",
+				"status order.canceled bob",
+			)
+
+			// Filled by the broker.
+			order, err = svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "TCS", Side: "sell", Quantity: 2, Price: 3500})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, report := range []models.ExecutionReport{
+				{BrokerOrderID: "B-1", OrderID: order.ID, Status: models.ExecutionAccepted, Timestamp: 100},
+				{BrokerOrderID: "B-1", Status: models.ExecutionFilled, Price: 3502, Timestamp: 200},
+			} {
+				if _, _, err := svc.ApplyExecutionReport(report); err != nil {
+					t.Fatal(err)
+				}
+			}
+			assertHistory(t, historyOf(t, svc, order.ID),
+				"status order.created ",
+				"status order.accepted broker",
+				"fill  broker",
+				"status order.filled broker",
+			)
+
+			// A scalper child executed by hand.
+			parent, err := svc.CreateScalperOrder(models.ScalperOrder{AccountID: "A1", Symbol: "SBIN", Quantity: 2, Legs: 2, ParentOrder: models.Order{Side: "buy", Price: 600}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			child := parent.ChildOrders[0].ID
+			if err := svc.ExecuteChildOrder(parent.ID, child, "carol"); err != nil {
+				t.Fatal(err)
+			}
+			got := historyOf(t, svc, child)
+			if len(got) < 2 || got[len(got)-2] != "fill  carol" || got[len(got)-1] != "status scalper.child_executed carol" {
+				t.Errorf("child history:\n%s", strings.Join(got, "\n"))
+			}
+
+			if _, err := svc.OrderHistory("missing"); err == nil {
+				t.Error("history of a missing order")
+			}
+		})
+	}
+}
+
+func TestGetOrderHistoryOverHTTP(t *testing.T) {
+	repo := repository.NewInMemoryOrderRepository()
+	svc := service.NewOMSService(repo)
+	router := api.SetupRoutes(repo, svc, callerSecret)
+	order, err := svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "ITC", Side: "buy", Quantity: 1, Price: 400})
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(method, path, account string, body ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(method, path, strings.NewReader(strings.Join(body, "")))
+		setCaller(r, models.Caller{AccountID: account, UserID: "u-" + account}, time.Now())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	// The actor is the caller, not whoever the body names.
+	modify := "/oms/scalper/order/" + order.ID + "/" + order.ID + "/modify"
+	if w := send(http.MethodPatch, modify, "A1", `{"version": 1, "price": 401, "actor": "u-A2"}`); w.Code != http.StatusOK {
+		t.Fatalf("modify: %d %s", w.Code, w.Body)
+	}
+	if w := send(http.MethodPost, "/oms/scalper/order/"+order.ID+"/"+order.ID+"/cancel", "A1"); w.Code != http.StatusOK {
+		t.Fatalf("cancel: %d %s", w.Code, w.Body)
+	}
+	w := send(http.MethodGet, "/orders/"+order.ID+"/history", "A1")
+	var history models.OrderHistory
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &history) != nil {
+		t.Fatalf("history: %d %s", w.Code, w.Body)
+	}
+	last := history.Entries[len(history.Entries)-1]
+	if history.OrderID != order.ID || last.Event != models.EventOrderCanceled || last.Actor != "u-A1" {
+		t.Errorf("history = %+v", history)
+	}
+	for _, entry := range history.Entries[1:] {
+		if entry.Actor != "u-A1" {
+			t.Errorf("%s %s made by %q", entry.Kind, entry.Event, entry.Actor)
+		}
+	}
+	if w := send(http.MethodGet, "/orders/"+order.ID+"/history", "A2"); w.Code != http.StatusForbidden {
+		t.Errorf("another account's history: %d", w.Code)
+	}
+	if w := send(http.MethodGet, "/orders/missing/history", "A1"); w.Code != http.StatusNotFound {
+		t.Errorf("missing order: %d", w.Code)
+	}
+}
@@ -0,0 +1,74 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestClientOrderIDMakesSubmissionIdempotent(t *testing.T) {
+	for name, repo := range map[string]repository.OrderRepository{
+		"memory": repository.NewInMemoryOrderRepository(),
+		"sql":    openSQLiteRepository(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewOMSService(repo)
+			submit := models.Order{ClientOrderID: "c-1", AccountID: "A1", Symbol: "INFY", Side: "buy", Quantity: 10, Price: 1500}
+
+			first, err := svc.CreateOrder(submit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			retry, err := svc.CreateOrder(submit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if retry.ID != first.ID {
+				t.Fatalf("retry created order %s, want the original %s", retry.ID, first.ID)
+			}
+
+			changed := submit
+			changed.Quantity = 20
+			if _, err := svc.CreateOrder(changed); !errors.Is(err, service.ErrIdempotencyMismatch) {
+				t.Fatalf("different order under the same id: err = %v", err)
+			}
+
+			// Client order ids are per account.
+			other := submit
+			other.AccountID = "A2"
+			if order, err := svc.CreateOrder(other); err != nil || order.ID == first.ID {
+				t.Fatalf("other account: order = %+v, err = %v", order, err)
+			}
+
+			orders, err := svc.GetOrders()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(orders) != 2 {
+				t.Fatalf("%d orders, want 2", len(orders))
+			}
+		})
+	}
+}
+
+func TestClientOrderIDIsForgottenAfterTheWindow(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(), service.WithIdempotencyWindow(time.Nanosecond))
+	submit := models.Order{ClientOrderID: "c-1", Symbol: "INFY", Side: "buy", Quantity: 10, Price: 1500}
+
+	first, err := svc.CreateOrder(submit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	submit.Quantity = 20
+	second, err := svc.CreateOrder(submit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID == first.ID {
+		t.Fatal("expired client order id returned the original order")
+	}
+}
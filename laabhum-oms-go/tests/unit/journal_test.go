@@ -0,0 +1,206 @@
+package unit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/journal"
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+// openJournaledService opens the journal in dir, replays it into a fresh
+// in-memory repository and returns a service writing to both.
+func openJournaledService(t *testing.T, dir string, checkpointEvery int) (*service.OMSService, *repository.InMemoryOrderRepository, *journal.Journal) {
+	t.Helper()
+	repo := repository.NewInMemoryOrderRepository()
+	j, err := journal.Open(dir, repo, checkpointEvery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Replay(func(ev models.Event) error { return repository.ApplyEvent(repo, ev) }); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { j.Close() })
+	return service.NewOMSService(repo, service.WithJournal(j)), repo, j
+}
+
+func TestJournalRebuildsStoreOnReplay(t *testing.T) {
+	dir := t.TempDir()
+	svc, _, j := openJournaledService(t, dir, 2)
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		order, err := svc.CreateOrder(models.Order{Symbol: "AAPL", Side: "buy", Quantity: 10, Price: 150})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, order.ID)
+	}
+	if err := svc.CancelOrder(ids[4], ids[4], ""); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+
+	// Simulate a crash in the middle of writing a record.
+	wal, err := os.OpenFile(filepath.Join(dir, "wal.log"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wal.WriteString(`{"seq":99,"type":"order.cre`)
+	wal.Close()
+
+	_, repo, reopened := openJournaledService(t, dir, 2)
+	orders, _ := repo.GetOrders()
+	if len(orders) != 5 {
+		t.Fatalf("len(orders) = %d, want 5", len(orders))
+	}
+	canceled, err := repo.GetOrder(ids[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canceled.Status != models.OrderStatusCanceled {
+		t.Errorf("status = %q, want canceled", canceled.Status)
+	}
+
+	events, err := reopened.Events(ids[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Type != models.EventOrderCreated || events[1].Type != models.EventOrderCanceled {
+		t.Errorf("unexpected audit trail: %+v", events)
+	}
+}
+
+// stringStore is a Snapshotter whose whole state is one string.
+type stringStore struct{ state string }
+
+func (s *stringStore) WriteSnapshot(w io.Writer) error {
+	_, err := io.WriteString(w, s.state)
+	return err
+}
+
+func (s *stringStore) ReadSnapshot(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	s.state = string(data)
+	return err
+}
+
+// openJournal opens and replays the journal in dir, returning the sequence
+// numbers of the events it replayed.
+func openJournal(t *testing.T, dir string, store journal.Snapshotter, checkpointEvery int) (*journal.Journal, []uint64) {
+	t.Helper()
+	j, err := journal.Open(dir, store, checkpointEvery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var replayed []uint64
+	if err := j.Replay(func(ev models.Event) error {
+		replayed = append(replayed, ev.Seq)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { j.Close() })
+	return j, replayed
+}
+
+func appendEvents(t *testing.T, j *journal.Journal, orderIDs ...string) {
+	t.Helper()
+	for _, id := range orderIDs {
+		if err := j.Append(&models.Event{Type: models.EventOrderCreated, OrderID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestJournalDiscardsTornFinalLine(t *testing.T) {
+	dir := t.TempDir()
+	j, _ := openJournal(t, dir, &stringStore{}, 0)
+	appendEvents(t, j, "O1", "O2", "O3")
+	j.Close()
+
+	path := filepath.Join(dir, "wal.log")
+	intact, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, append(intact, `{"seq":4,"type":"order.cre`...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	j, replayed := openJournal(t, dir, &stringStore{}, 0)
+	if fmt.Sprint(replayed) != "[1 2 3]" {
+		t.Errorf("replayed %v, want [1 2 3]", replayed)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, intact) {
+		t.Errorf("torn record left in the wal:\n%s", data)
+	}
+
+	// The next record takes the torn one's place.
+	appendEvents(t, j, "O4")
+	j.Close()
+	_, replayed = openJournal(t, dir, &stringStore{}, 0)
+	if fmt.Sprint(replayed) != "[1 2 3 4]" {
+		t.Errorf("replayed %v, want [1 2 3 4]", replayed)
+	}
+}
+
+func TestJournalCheckpointArchivesTheWal(t *testing.T) {
+	dir := t.TempDir()
+	store := &stringStore{}
+	j, _ := openJournal(t, dir, store, 2)
+	for i, id := range []string{"O1", "O2", "O3", "O4", "O1"} {
+		appendEvents(t, j, id)
+		store.state = fmt.Sprintf("applied %d", i+1)
+	}
+
+	// Checkpoints were taken before the third and fifth appends.
+	segments, err := filepath.Glob(filepath.Join(dir, "archive", "wal-*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 2 ||
+		filepath.Base(segments[0]) != fmt.Sprintf("wal-%020d-%020d.log", 1, 2) ||
+		filepath.Base(segments[1]) != fmt.Sprintf("wal-%020d-%020d.log", 3, 4) {
+		t.Fatalf("archive = %v", segments)
+	}
+	events, err := j.Events("O1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Seq != 1 || events[1].Seq != 5 {
+		t.Errorf("events of O1 = %+v", events)
+	}
+	j.Close()
+
+	// Replay restores the checkpoint and applies only the wal after it.
+	restored := &stringStore{}
+	_, replayed := openJournal(t, dir, restored, 2)
+	if restored.state != "applied 4" || fmt.Sprint(replayed) != "[5]" {
+		t.Errorf("restored %q and replayed %v, want \"applied 4\" and [5]", restored.state, replayed)
+	}
+
+	// A crash after installing a checkpoint but before rotating the wal
+	// leaves events the checkpoint already covers; replay skips them.
+	archived, err := os.ReadFile(segments[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	wal, err := os.ReadFile(filepath.Join(dir, "wal.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "wal.log"), append(archived, wal...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, replayed = openJournal(t, dir, &stringStore{}, 2)
+	if fmt.Sprint(replayed) != "[5]" {
+		t.Errorf("replayed %v after an interrupted checkpoint, want [5]", replayed)
+	}
+}
@@ -0,0 +1,88 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestKillSwitchBlocksCancelsAndFlattens(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+
+	create := func(account string, quantity int) *models.Order {
+		t.Helper()
+		order, err := svc.CreateOrder(models.Order{AccountID: account, Symbol: "HDFC", Side: "buy", Quantity: quantity, Price: 1500})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return order
+	}
+	filled := create("A1", 10)
+	if _, _, err := svc.RecordFill(models.Fill{OrderID: filled.ID, ExecutionID: "k-1", Quantity: 4, Price: 1500}); err != nil {
+		t.Fatal(err)
+	}
+	resting := create("A1", 5)
+	other := create("A2", 5)
+
+	ks, err := svc.EngageKillSwitch(service.KillSwitchRequest{AccountID: "A1", Actor: "risk-desk", Reason: "runaway algo", Flatten: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ks.Engaged || len(ks.Audit) != 1 || ks.Audit[0].Canceled != 2 || ks.Audit[0].Exits != 1 {
+		t.Fatalf("kill switch = %+v", ks)
+	}
+
+	orders, err := svc.GetOrders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, order := range orders {
+		switch {
+		case order.ID == resting.ID || order.ID == filled.ID:
+			if order.Status != models.OrderStatusCanceled {
+				t.Errorf("order %s status = %s, want canceled", order.ID, order.Status)
+			}
+		case order.ID == other.ID:
+			if order.Status != models.OrderStatusPending {
+				t.Errorf("other account's order status = %s", order.Status)
+			}
+		case order.Role == models.OrderRoleExit:
+			if order.Quantity != 4 || order.Side != "sell" || order.AccountID != "A1" {
+				t.Errorf("exit = %+v", order)
+			}
+		}
+	}
+
+	var riskErr *service.RiskError
+	if _, err := svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "HDFC", Side: "buy", Quantity: 1, Price: 1500}); !errors.As(err, &riskErr) || riskErr.Code != service.RiskKillSwitch {
+		t.Fatalf("order while engaged: err = %v", err)
+	}
+	create("A2", 1)
+
+	if _, err := svc.ReleaseKillSwitch(service.KillSwitchRequest{AccountID: "A1", Actor: "risk-desk"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ReleaseKillSwitch(service.KillSwitchRequest{AccountID: "A1"}); !errors.Is(err, service.ErrKillSwitchNotEngaged) {
+		t.Fatalf("second release: err = %v", err)
+	}
+	create("A1", 1)
+
+	// The OMS-wide switch blocks every account.
+	if _, err := svc.EngageKillSwitch(service.KillSwitchRequest{Reason: "exchange outage"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateOrder(models.Order{AccountID: "A2", Symbol: "HDFC", Side: "buy", Quantity: 1, Price: 1500}); !errors.As(err, &riskErr) || riskErr.Code != service.RiskKillSwitch {
+		t.Fatalf("order under global switch: err = %v", err)
+	}
+
+	switches, err := svc.GetKillSwitches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(switches) != 2 || switches[0].AccountID != "" || len(switches[1].Audit) != 2 {
+		t.Fatalf("kill switches = %+v", switches)
+	}
+}
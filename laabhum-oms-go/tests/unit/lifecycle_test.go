@@ -0,0 +1,51 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestCreateOrderStartsPending(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+
+	order, err := svc.CreateOrder(models.Order{Symbol: "AAPL", Side: "buy", Quantity: 10, Price: 150, Status: "filled"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order.Status != models.OrderStatusPending {
+		t.Errorf("status = %q, want %q", order.Status, models.OrderStatusPending)
+	}
+	if len(order.Transitions) != 1 || order.Transitions[0].From != models.OrderStatusNew {
+		t.Errorf("unexpected transitions: %+v", order.Transitions)
+	}
+}
+
+func TestCanceledOrderCannotReopen(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+
+	order, err := svc.CreateOrder(models.Order{Symbol: "AAPL", Side: "buy", Quantity: 10, Price: 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CancelOrder(order.ID, order.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	canceled, err := svc.GetOrder(order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	price := 151.0
+	_, err = svc.AmendOrder(order.ID, order.ID, models.AmendRequest{Version: canceled.Version, Price: &price})
+	if !errors.Is(err, service.ErrInvalidTransition) {
+		t.Fatalf("err = %v, want ErrInvalidTransition", err)
+	}
+	if err := svc.CancelOrder(order.ID, order.ID, ""); !errors.Is(err, service.ErrInvalidTransition) {
+		t.Fatalf("second cancel err = %v, want ErrInvalidTransition", err)
+	}
+}
@@ -0,0 +1,115 @@
+package unit
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+)
+
+func TestInMemoryRepositoryConcurrentWrites(t *testing.T) {
+	repo := repository.NewInMemoryOrderRepository()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			order := models.Order{ID: fmt.Sprintf("o-%d", i), Symbol: "AAPL", Quantity: 1}
+			if _, err := repo.CreateOrder(order); err != nil {
+				t.Error(err)
+			}
+			order.Quantity = 2
+			if err := repo.UpdateOrder(&order); err != nil {
+				t.Error(err)
+			}
+			if _, err := repo.GetOrders(); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	orders, _ := repo.GetOrders()
+	if len(orders) != 50 {
+		t.Errorf("len(orders) = %d, want 50", len(orders))
+	}
+}
+
+func TestInMemoryRepositorySnapshotRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oms.snapshot.json")
+
+	repo := repository.NewInMemoryOrderRepository()
+	if _, err := repo.CreateOrder(models.Order{ID: "o-1", Symbol: "AAPL", Quantity: 5, Status: models.OrderStatusPending}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SnapshotToFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := repository.NewInMemoryOrderRepository()
+	if err := restored.RestoreFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := restored.GetOrder("o-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Quantity != 5 || got.Status != models.OrderStatusPending {
+		t.Errorf("restored order = %+v", got)
+	}
+
+	if err := repository.NewInMemoryOrderRepository().RestoreFromFile(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("restoring a missing snapshot: %v", err)
+	}
+}
+
+func TestGetWorkingOrders(t *testing.T) {
+	repos := map[string]repository.OrderRepository{
+		"memory": repository.NewInMemoryOrderRepository(),
+		"sql":    openSQLiteRepository(t),
+	}
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			for i, status := range []models.OrderStatus{models.OrderStatusOpen, models.OrderStatusFilled, models.OrderStatusNew, models.OrderStatusCanceled} {
+				order := models.Order{ID: fmt.Sprintf("o-%d", i), Symbol: "AAPL", Quantity: 1, Status: status, CreatedAt: int64(i)}
+				if _, err := repo.CreateOrder(order); err != nil {
+					t.Fatal(err)
+				}
+			}
+			order, err := repo.GetOrder("o-0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			order.Status = models.OrderStatusExpired
+			if err := repo.UpdateOrder(order); err != nil {
+				t.Fatal(err)
+			}
+
+			working, err := repo.GetWorkingOrders()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(working) != 1 || working[0].ID != "o-2" {
+				t.Errorf("working orders = %+v", working)
+			}
+
+			if memory, ok := repo.(*repository.InMemoryOrderRepository); ok {
+				path := filepath.Join(t.TempDir(), "oms.snapshot.json")
+				if err := memory.SnapshotToFile(path); err != nil {
+					t.Fatal(err)
+				}
+				restored := repository.NewInMemoryOrderRepository()
+				if err := restored.RestoreFromFile(path); err != nil {
+					t.Fatal(err)
+				}
+				if working, _ := restored.GetWorkingOrders(); len(working) != 1 || working[0].ID != "o-2" {
+					t.Errorf("working orders after restore = %+v", working)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/pkg/nats"
+)
+
+func TestOrderSubject(t *testing.T) {
+	for symbol, want := range map[string]string{
+		"INFY":       "oms.orders.INFY.filled",
+		"NIFTY 50":   "oms.orders.NIFTY_50.filled",
+		"BRK.B":      "oms.orders.BRK_B.filled",
+		"":           "oms.orders._.filled",
+		"M&M":        "oms.orders.M&M.filled",
+		"BANKNIFTY*": "oms.orders.BANKNIFTY_.filled",
+	} {
+		event := models.OrderEvent{Kind: models.OrderEventFilled, Order: models.Order{Symbol: symbol}}
+		if got := nats.OrderSubject(event); got != want {
+			t.Errorf("OrderSubject(%q) = %q, want %q", symbol, got, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestCreateOrderValidatesOrderTypes(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+
+	tests := []struct {
+		name  string
+		order models.Order
+		ok    bool
+	}{
+		{"market", models.Order{OrderType: models.OrderTypeMarket}, true},
+		{"market with price", models.Order{OrderType: models.OrderTypeMarket, Price: 100}, false},
+		{"implicit limit", models.Order{Price: 100}, true},
+		{"limit without price", models.Order{OrderType: models.OrderTypeLimit}, false},
+		{"stop", models.Order{OrderType: models.OrderTypeStop, TriggerPrice: 105}, true},
+		{"stop without trigger", models.Order{OrderType: models.OrderTypeStop}, false},
+		{"stop limit", models.Order{OrderType: models.OrderTypeStopLimit, TriggerPrice: 105, Price: 106}, true},
+		{"buy stop limit below trigger", models.Order{OrderType: models.OrderTypeStopLimit, TriggerPrice: 105, Price: 104}, false},
+		{"IOC stop", models.Order{OrderType: models.OrderTypeStop, TriggerPrice: 105, TimeInForce: models.TimeInForceIOC}, false},
+		{"GTD without expiry", models.Order{Price: 100, TimeInForce: models.TimeInForceGTD}, false},
+		{"unknown type", models.Order{OrderType: "iceberg"}, false},
+	}
+	for _, tt := range tests {
+		tt.order.Symbol, tt.order.Side, tt.order.Quantity = "TCS", "buy", 1
+		_, err := svc.CreateOrder(tt.order)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v, want ok = %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestExpireOrdersByTimeInForce(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(),
+		service.WithTradingSession(service.TradingSession{CloseHour: 15, CloseMinute: 30, Location: time.UTC}))
+
+	now := time.Now()
+	create := func(tif models.TimeInForce, expiresAt int64) *models.Order {
+		t.Helper()
+		order, err := svc.CreateOrder(models.Order{Symbol: "TCS", Side: "buy", Quantity: 1, Price: 100, TimeInForce: tif, ExpiresAt: expiresAt})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return order
+	}
+	day := create(models.TimeInForceDay, 0)
+	gtc := create(models.TimeInForceGTC, 0)
+	gtd := create(models.TimeInForceGTD, now.Add(48*time.Hour).Unix())
+
+	if expired, err := svc.ExpireOrders(now); err != nil || len(expired) != 0 {
+		t.Fatalf("nothing should expire yet: %v, %v", expired, err)
+	}
+
+	// Past the next session close but before the GTD expiry.
+	expired, err := svc.ExpireOrders(now.Add(25 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 || expired[0].ID != day.ID || expired[0].Status != models.OrderStatusExpired {
+		t.Fatalf("expired = %+v, want only the DAY order", expired)
+	}
+
+	expired, err = svc.ExpireOrders(now.Add(72 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 || expired[0].ID != gtd.ID {
+		t.Fatalf("expired = %+v, want only the GTD order", expired)
+	}
+
+	orders, _ := svc.GetOrders()
+	for _, order := range orders {
+		if order.ID == gtc.ID && order.Status != models.OrderStatusPending {
+			t.Errorf("GTC order is %q, want pending", order.Status)
+		}
+	}
+}
+
+func TestIOCOrdersEndAtTheirFirstExecutionReport(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(),
+		service.WithTradingSession(service.TradingSession{CloseHour: 15, CloseMinute: 30, Location: time.UTC}))
+	create := func() *models.Order {
+		t.Helper()
+		order, err := svc.CreateOrder(models.Order{Symbol: "TCS", Side: "buy", Quantity: 10, Price: 100, TimeInForce: models.TimeInForceIOC})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return order
+	}
+	unfilled, partial := create(), create()
+
+	// The session close does not expire them.
+	if expired, err := svc.ExpireOrders(time.Now().Add(25 * time.Hour)); err != nil || len(expired) != 0 {
+		t.Fatalf("expired %+v, %v", expired, err)
+	}
+
+	order, _, err := svc.ApplyExecutionReport(models.ExecutionReport{BrokerOrderID: "B-1", OrderID: unfilled.ID, Status: models.ExecutionAccepted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != models.OrderStatusCanceled {
+		t.Errorf("IOC accepted without a fill is %s, want canceled", order.Status)
+	}
+	if _, _, err := svc.RecordFill(models.Fill{OrderID: partial.ID, ExecutionID: "e-1", Quantity: 4, Price: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if order, err = svc.GetOrder(partial.ID); err != nil || order.Status != models.OrderStatusCanceled || order.FilledQuantity != 4 {
+		t.Errorf("partially filled IOC = %+v, %v", order, err)
+	}
+
+	// The legs of an IOC bracket entry stand for the day.
+	parent, err := svc.CreateScalperOrder(models.ScalperOrder{
+		Symbol:      "TCS",
+		Quantity:    10,
+		ParentOrder: models.Order{Side: "buy", Price: 100, TimeInForce: models.TimeInForceIOC},
+		Bracket:     &models.Bracket{TargetPrice: 110, StopLossPrice: 95},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, target, stop := bracketLegs(t, svc, parent.ID)
+	if entry.TimeInForce != models.TimeInForceIOC || target.TimeInForce != models.TimeInForceDay || stop.TimeInForce != models.TimeInForceDay {
+		t.Errorf("entry is %s and legs %s and %s, want IOC, DAY and DAY", entry.TimeInForce, target.TimeInForce, stop.TimeInForce)
+	}
+}
@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+// flakyPublisher fails while down is set.
+type flakyPublisher struct {
+	recordingPublisher
+	down bool
+}
+
+func (p *flakyPublisher) Publish(events []models.OrderEvent) error {
+	if p.down {
+		return errors.New("broker unavailable")
+	}
+	return p.recordingPublisher.Publish(events)
+}
+
+func TestOutboxRetriesUntilDelivered(t *testing.T) {
+	for name, repo := range map[string]repository.OrderRepository{
+		"memory": repository.NewInMemoryOrderRepository(),
+		"sql":    openSQLiteRepository(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			publisher := &flakyPublisher{down: true}
+			svc := service.NewOMSService(repo, service.WithEventPublisher(publisher))
+
+			order, err := svc.CreateOrder(models.Order{Symbol: "SBIN", Side: "buy", Quantity: 5, Price: 600})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := svc.CancelOrder(order.ID, "", ""); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := svc.RelayOutbox(1); err == nil {
+				t.Fatal("relay succeeded while the publisher was down")
+			}
+			pending, err := repo.PendingOutbox(10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pending) != 2 || pending[0].Kind != models.OrderEventCreated || pending[1].Kind != models.OrderEventCanceled {
+				t.Fatalf("pending = %+v", pending)
+			}
+
+			publisher.down = false
+			for _, want := range []int{1, 1, 0} {
+				if n, err := svc.RelayOutbox(1); err != nil || n != want {
+					t.Fatalf("relay = %d, %v; want %d", n, err, want)
+				}
+			}
+			if len(publisher.events) != 2 || publisher.events[0].ID != pending[0].ID || publisher.events[1].ID != pending[1].ID {
+				t.Fatalf("published %+v", publisher.events)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestPositionsFromTrades(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+
+	buy, err := svc.CreateOrder(models.Order{Symbol: "INFY", Side: "buy", Quantity: 10, Price: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sell, err := svc.CreateOrder(models.Order{Symbol: "INFY", Side: "sell", Quantity: 14, Price: 105})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fills := []models.Fill{
+		{OrderID: buy.ID, ExecutionID: "e-1", Quantity: 10, Price: 100, Timestamp: 1},
+		{OrderID: sell.ID, ExecutionID: "e-2", Quantity: 4, Price: 110, Timestamp: 2},
+		// Closes the remaining 6 long and goes 4 short at 105.
+		{OrderID: sell.ID, ExecutionID: "e-3", Quantity: 10, Price: 105, Timestamp: 3},
+	}
+	for _, fill := range fills {
+		if _, _, err := svc.RecordFill(fill); err != nil {
+			t.Fatal(err)
+		}
+	}
+	svc.Prices().Update("INFY", 100)
+
+	check := func(label string) {
+		t.Helper()
+		positions := svc.GetPositions("", "INFY", "")
+		if len(positions) != 1 {
+			t.Fatalf("%s: positions = %+v", label, positions)
+		}
+		p := positions[0]
+		if p.ID != "INFY:MIS" || p.Quantity != -4 || p.AvgPrice != 105 || p.BuyQuantity != 10 || p.SellQuantity != 14 {
+			t.Errorf("%s: position = %+v", label, p)
+		}
+		if math.Abs(p.RealizedPnL-70) > 1e-9 || math.Abs(p.UnrealizedPnL-20) > 1e-9 {
+			t.Errorf("%s: realized = %v, unrealized = %v, want 70 and 20", label, p.RealizedPnL, p.UnrealizedPnL)
+		}
+	}
+	check("live")
+
+	if err := svc.SyncPositions(); err != nil {
+		t.Fatal(err)
+	}
+	svc.Prices().Update("INFY", 100)
+	check("rebuilt")
+
+	if got := svc.GetPositions("", "TCS", ""); len(got) != 0 {
+		t.Errorf("unknown symbol returned %+v", got)
+	}
+}
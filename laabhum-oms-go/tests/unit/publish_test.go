@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+type recordingPublisher struct {
+	events []models.OrderEvent
+}
+
+func (p *recordingPublisher) Publish(events []models.OrderEvent) error {
+	p.events = append(p.events, events...)
+	return nil
+}
+
+func TestOrderLifecycleEventsArePublished(t *testing.T) {
+	publisher := &recordingPublisher{}
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(),
+		service.WithEventPublisher(publisher),
+		service.WithRiskChecks(service.MaxQuantityCheck{Max: 100}))
+
+	order, err := svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "ITC", Side: "buy", Quantity: 10, Price: 400})
+	if err != nil {
+		t.Fatal(err)
+	}
+	price := 401.0
+	if _, err := svc.AmendOrder(order.ID, "", models.AmendRequest{Version: order.Version, Price: &price}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.RecordFill(models.Fill{OrderID: order.ID, ExecutionID: "p-1", Quantity: 4, Price: 401}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CancelOrder(order.ID, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateOrder(models.Order{Symbol: "ITC", Side: "buy", Quantity: 500, Price: 400}); err == nil {
+		t.Fatal("oversized order accepted")
+	}
+	if _, err := svc.RelayOutbox(100); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []models.OrderEventKind{
+		models.OrderEventCreated,
+		models.OrderEventModified,
+		models.OrderEventFilled,
+		models.OrderEventCanceled,
+		models.OrderEventRejected,
+	}
+	if len(publisher.events) != len(want) {
+		t.Fatalf("published %d events, want %d: %+v", len(publisher.events), len(want), publisher.events)
+	}
+	for i, event := range publisher.events {
+		if event.Kind != want[i] || event.Version != models.OrderEventVersion || event.ID == "" {
+			t.Errorf("event %d = %s v%d, want %s", i, event.Kind, event.Version, want[i])
+		}
+		if i < 4 && (event.OrderID != order.ID || event.AccountID != "A1") {
+			t.Errorf("event %d is about %s/%s", i, event.AccountID, event.OrderID)
+		}
+	}
+	if fill := publisher.events[2]; len(fill.Trades) != 1 || fill.Order.FilledQuantity != 4 {
+		t.Errorf("fill event = %+v", fill)
+	}
+
+	// Scalper events only report the children that changed.
+	parent, err := svc.CreateScalperOrder(models.ScalperOrder{Symbol: "ITC", Quantity: 10, Legs: 2, ParentOrder: models.Order{Side: "buy", Price: 400}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RelayOutbox(100); err != nil {
+		t.Fatal(err)
+	}
+	publisher.events = nil
+	if err := svc.ExecuteChildOrder(parent.ID, parent.ChildOrders[0].ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RelayOutbox(100); err != nil {
+		t.Fatal(err)
+	}
+	if len(publisher.events) != 1 || publisher.events[0].Kind != models.OrderEventFilled || publisher.events[0].ParentID != parent.ID {
+		t.Fatalf("child execution published %+v", publisher.events)
+	}
+}
+
+func TestTriggeredStopIsPublishedForRouting(t *testing.T) {
+	publisher := &recordingPublisher{}
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(), service.WithEventPublisher(publisher))
+	stop, err := svc.CreateOrder(models.Order{Symbol: "ITC", Side: "sell", Quantity: 5, OrderType: models.OrderTypeStop, TriggerPrice: 395})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.OnPrice("ITC", 394); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RelayOutbox(100); err != nil {
+		t.Fatal(err)
+	}
+	if len(publisher.events) != 2 {
+		t.Fatalf("published %+v", publisher.events)
+	}
+	triggered := publisher.events[1]
+	if triggered.Kind != models.OrderEventTriggered || triggered.OrderID != stop.ID || triggered.Order.Status != models.OrderStatusOpen {
+		t.Errorf("triggered event = %+v", triggered)
+	}
+}
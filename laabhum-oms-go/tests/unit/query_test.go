@@ -0,0 +1,155 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/api"
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestQueryOrdersFiltersSortsAndPages(t *testing.T) {
+	repos := map[string]repository.OrderRepository{
+		"memory": repository.NewInMemoryOrderRepository(),
+		"sql":    openSQLiteRepository(t),
+	}
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewOMSService(repo)
+			var ids []string
+			for _, order := range []models.Order{
+				{AccountID: "A1", Symbol: "INFY", Side: "buy", Quantity: 1, Price: 100},
+				{AccountID: "A1", Symbol: "TCS", Side: "sell", Quantity: 1, Price: 100},
+				{AccountID: "A2", Symbol: "INFY", Side: "sell", Quantity: 1, Price: 100},
+				{AccountID: "A1", Symbol: "WIPRO", Side: "buy", Quantity: 1, Price: 100},
+				{AccountID: "A1", Symbol: "HDFC", Side: "buy", Quantity: 1, Price: 100},
+			} {
+				created, err := svc.CreateOrder(order)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, created.ID)
+			}
+			if err := svc.CancelOrder(ids[3], "", ""); err != nil {
+				t.Fatal(err)
+			}
+			parent, err := svc.CreateScalperOrder(models.ScalperOrder{AccountID: "A1", Symbol: "SBIN", Quantity: 4, Legs: 2, ParentOrder: models.Order{Side: "buy", Price: 600}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			query := func(q service.OrderQuery) *service.OrderPage {
+				t.Helper()
+				page, err := svc.QueryOrders(q)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return page
+			}
+
+			// Paging through A1's standalone orders in two-order pages.
+			var symbols []string
+			seen := make(map[string]bool)
+			q := service.OrderQuery{Filter: service.OrderFilter{AccountID: "A1", Side: "buy"}, Sort: "-symbol", Limit: 2}
+			for pages := 1; ; pages++ {
+				page := query(q)
+				for _, order := range page.Orders {
+					if seen[order.ID] {
+						t.Fatalf("order %s on two pages", order.ID)
+					}
+					seen[order.ID] = true
+					symbols = append(symbols, order.Symbol)
+				}
+				if page.NextCursor == "" {
+					if pages != 3 {
+						t.Errorf("%d pages, want 3", pages)
+					}
+					break
+				}
+				q.Cursor = page.NextCursor
+			}
+			want := []string{"WIPRO", "SBIN", "SBIN", "INFY", "HDFC"}
+			if len(symbols) != len(want) {
+				t.Fatalf("symbols = %v, want %v", symbols, want)
+			}
+			for i := range want {
+				if symbols[i] != want[i] {
+					t.Fatalf("symbols = %v, want %v", symbols, want)
+				}
+			}
+
+			page := query(service.OrderQuery{Filter: service.OrderFilter{Statuses: []models.OrderStatus{models.OrderStatusCanceled}}})
+			if len(page.Orders) != 1 || page.Orders[0].ID != ids[3] {
+				t.Errorf("canceled orders = %+v", page.Orders)
+			}
+			page = query(service.OrderQuery{Filter: service.OrderFilter{ParentID: parent.ID}})
+			if len(page.Orders) != 2 || page.Orders[0].CreatedAt > page.Orders[1].CreatedAt {
+				t.Errorf("children = %+v", page.Orders)
+			}
+			page = query(service.OrderQuery{Filter: service.OrderFilter{Symbol: "infy", CreatedTo: page.Orders[0].CreatedAt + 1}})
+			if len(page.Orders) != 2 {
+				t.Errorf("INFY orders = %+v", page.Orders)
+			}
+
+			if _, err := svc.QueryOrders(service.OrderQuery{Sort: "price"}); !errors.Is(err, service.ErrInvalidQuery) {
+				t.Errorf("sort by price: err = %v", err)
+			}
+			first := query(service.OrderQuery{Limit: 1})
+			if _, err := svc.QueryOrders(service.OrderQuery{Sort: "symbol", Cursor: first.NextCursor}); !errors.Is(err, service.ErrInvalidQuery) {
+				t.Errorf("cursor of another sort: err = %v", err)
+			}
+		})
+	}
+}
+
+func TestGetOrdersOverHTTP(t *testing.T) {
+	repo := repository.NewInMemoryOrderRepository()
+	svc := service.NewOMSService(repo)
+	router := api.SetupRoutes(repo, svc, callerSecret)
+	var ids []string
+	for _, account := range []string{"A1", "A1", "A2"} {
+		order, err := svc.CreateOrder(models.Order{AccountID: account, Symbol: "ITC", Side: "buy", Quantity: 1, Price: 400})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, order.ID)
+	}
+	get := func(path, account string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		setCaller(r, models.Caller{AccountID: account}, time.Now())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w := get("/orders?limit=1&status=pending,open&symbol=ITC", "A1")
+	var orders []models.Order
+	if err := json.Unmarshal(w.Body.Bytes(), &orders); err != nil || len(orders) != 1 || w.Header().Get("X-Next-Cursor") == "" {
+		t.Fatalf("first page: %d %s %v", w.Code, w.Body, w.Header())
+	}
+	w = get("/orders?limit=1&status=pending,open&symbol=ITC&cursor="+w.Header().Get("X-Next-Cursor"), "A1")
+	if err := json.Unmarshal(w.Body.Bytes(), &orders); err != nil || len(orders) != 1 || w.Header().Get("X-Next-Cursor") != "" {
+		t.Fatalf("last page: %d %s %v", w.Code, w.Body, w.Header())
+	}
+	if w := get("/orders?created_from=yesterday", "A1"); w.Code != http.StatusBadRequest {
+		t.Errorf("bad created_from: %d", w.Code)
+	}
+
+	var order models.Order
+	if w := get("/orders/"+ids[0], "A1"); w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &order) != nil || order.ID != ids[0] {
+		t.Fatalf("get order: %d %s", w.Code, w.Body)
+	}
+	if w := get("/orders/"+ids[2], "A1"); w.Code != http.StatusForbidden {
+		t.Errorf("another account's order: %d", w.Code)
+	}
+	if w := get("/orders/missing", "A1"); w.Code != http.StatusNotFound {
+		t.Errorf("missing order: %d", w.Code)
+	}
+}
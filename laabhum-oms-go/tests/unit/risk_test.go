@@ -0,0 +1,136 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestRiskChecksRejectWithReasonCode(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(), service.WithRiskChecks(service.RiskChecks(service.RiskLimits{
+		RestrictedSymbols:  []string{"yesbank"},
+		MaxQuantity:        100,
+		MaxNotional:        20000,
+		PriceBandPercent:   5,
+		MaxPosition:        150,
+		MaxOrdersPerSecond: 100,
+	})...))
+	if _, err := svc.OnPrice("INFY", 200); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		order models.Order
+		code  service.RiskCode
+	}{
+		{"restricted", models.Order{Symbol: "YESBANK", Side: "buy", Quantity: 1, Price: 20}, service.RiskRestrictedSymbol},
+		{"quantity", models.Order{Symbol: "INFY", Side: "buy", Quantity: 101, Price: 200}, service.RiskMaxQuantity},
+		{"notional", models.Order{Symbol: "INFY", Side: "buy", Quantity: 100, Price: 201}, service.RiskMaxNotional},
+		{"unpriced market", models.Order{Symbol: "TCS", Side: "buy", Quantity: 1}, service.RiskMaxNotional},
+		{"price band", models.Order{Symbol: "INFY", Side: "buy", Quantity: 10, Price: 211}, service.RiskPriceBand},
+	}
+	for _, c := range cases {
+		_, err := svc.CreateOrder(c.order)
+		var riskErr *service.RiskError
+		if !errors.As(err, &riskErr) || riskErr.Code != c.code {
+			t.Errorf("%s: err = %v, want %s", c.name, err, c.code)
+			continue
+		}
+		if riskErr.OrderID == "" {
+			t.Errorf("%s: rejected order was not recorded", c.name)
+		}
+	}
+
+	// The position limit counts filled quantity; reducing orders pass.
+	entry, err := svc.CreateOrder(models.Order{Symbol: "INFY", Side: "buy", Quantity: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.RecordFill(models.Fill{OrderID: entry.ID, ExecutionID: "r-1", Quantity: 100, Price: 200}); err != nil {
+		t.Fatal(err)
+	}
+	var riskErr *service.RiskError
+	if _, err := svc.CreateOrder(models.Order{Symbol: "INFY", Side: "buy", Quantity: 60}); !errors.As(err, &riskErr) || riskErr.Code != service.RiskMaxPosition {
+		t.Fatalf("position limit: err = %v", err)
+	}
+	if _, err := svc.CreateOrder(models.Order{Symbol: "INFY", Side: "sell", Quantity: 100}); err != nil {
+		t.Fatalf("reducing order rejected: %v", err)
+	}
+
+	orders, err := svc.GetOrders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rejected := 0
+	for _, order := range orders {
+		if order.Status == models.OrderStatusRejected {
+			rejected++
+		}
+	}
+	if rejected != len(cases)+1 {
+		t.Fatalf("rejected orders = %d, want %d", rejected, len(cases)+1)
+	}
+}
+
+func TestOrderRateCheckIsPerAccount(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(), service.WithRiskChecks(service.NewOrderRateCheck(2)))
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "SBIN", Side: "buy", Quantity: 1, Price: 500}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var riskErr *service.RiskError
+	if _, err := svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "SBIN", Side: "buy", Quantity: 1, Price: 500}); !errors.As(err, &riskErr) || riskErr.Code != service.RiskOrderRate {
+		t.Fatalf("third order: err = %v", err)
+	}
+	if _, err := svc.CreateOrder(models.Order{AccountID: "A2", Symbol: "SBIN", Side: "buy", Quantity: 1, Price: 500}); err != nil {
+		t.Fatalf("other account throttled: %v", err)
+	}
+}
+
+func TestDailyLossCheckCountsOnlyTheSession(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(),
+		service.WithTradingSession(service.TradingSession{CloseHour: 15, CloseMinute: 30, Location: time.UTC}),
+		service.WithRiskChecks(service.DailyLossCheck{Max: 120}))
+	trade := func(side string, price float64, at time.Time) {
+		t.Helper()
+		order, err := svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "INFY", Side: side, Quantity: 10, Price: price})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := svc.RecordFill(models.Fill{OrderID: order.ID, ExecutionID: order.ID, Quantity: 10, Price: price, Timestamp: at.Unix()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertAllowed := func(want bool) {
+		t.Helper()
+		_, err := svc.CreateOrder(models.Order{AccountID: "A1", Symbol: "INFY", Side: "buy", Quantity: 1, Price: 100})
+		var riskErr *service.RiskError
+		if rejected := errors.As(err, &riskErr) && riskErr.Code == service.RiskMaxDailyLoss; rejected == want {
+			t.Errorf("entry allowed = %v, want %v: %v", !rejected, want, err)
+		}
+	}
+
+	// A loss of 150 in an earlier session does not count today.
+	yesterday := time.Now().Add(-48 * time.Hour)
+	trade("buy", 100, yesterday)
+	trade("sell", 85, yesterday)
+	assertAllowed(true)
+
+	// A loss of 120 today reaches the limit.
+	trade("buy", 100, time.Now())
+	trade("sell", 88, time.Now())
+	assertAllowed(false)
+
+	// So it does with the book rebuilt from the repository.
+	if err := svc.SyncPositions(); err != nil {
+		t.Fatal(err)
+	}
+	assertAllowed(false)
+}
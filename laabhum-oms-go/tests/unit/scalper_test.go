@@ -0,0 +1,85 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestScalperOrderSlicesIntoChildren(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository(),
+		service.WithSlicingRule(service.SlicingRule{Legs: 2, MaxChildQuantity: 4}))
+
+	order, err := svc.CreateScalperOrder(models.ScalperOrder{
+		ParentOrder: models.Order{Symbol: "NIFTY", Side: "buy", Quantity: 10, Price: 100},
+		ChildOrders: []models.Order{{ID: "client-supplied"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 10 with at most 4 per child needs 3 legs: 4, 3, 3.
+	want := []int{4, 3, 3}
+	if len(order.ChildOrders) != len(want) {
+		t.Fatalf("got %d children, want %d", len(order.ChildOrders), len(want))
+	}
+	for i, child := range order.ChildOrders {
+		if child.Quantity != want[i] || child.ParentID != order.ID || child.Leg != i+1 {
+			t.Errorf("child %d = %+v", i, child)
+		}
+	}
+	if order.Status != models.ScalperStatusOpen {
+		t.Errorf("status = %q, want %q", order.Status, models.ScalperStatusOpen)
+	}
+}
+
+func TestScalperStatusDerivedFromChildren(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+
+	order, err := svc.CreateScalperOrder(models.ScalperOrder{
+		ParentOrder: models.Order{Symbol: "NIFTY", Side: "sell", Quantity: 9, Price: 100},
+		Legs:        3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	children := order.ChildOrders
+
+	if err := svc.ExecuteChildOrder(order.ID, children[0].ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	assertScalperStatus(t, svc, order.ID, models.ScalperStatusPartiallyExecuted)
+
+	if err := svc.CancelOrder(order.ID, children[1].ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ExecuteChildOrder(order.ID, children[1].ID, ""); err == nil {
+		t.Error("executing a canceled child should fail")
+	}
+
+	if err := svc.ExecuteAllChildOrders(order.ID); err != nil {
+		t.Fatal(err)
+	}
+	assertScalperStatus(t, svc, order.ID, models.ScalperStatusPartiallyExecuted)
+
+	other, _ := svc.CreateScalperOrder(models.ScalperOrder{
+		ParentOrder: models.Order{Symbol: "NIFTY", Side: "buy", Quantity: 2, Price: 100},
+	})
+	if err := svc.ExecuteAllChildOrders(other.ID); err != nil {
+		t.Fatal(err)
+	}
+	assertScalperStatus(t, svc, other.ID, models.ScalperStatusFullyExecuted)
+}
+
+func assertScalperStatus(t *testing.T, svc *service.OMSService, parentID, want string) {
+	t.Helper()
+	order, err := svc.GetScalperOrder(parentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != want {
+		t.Errorf("status = %q, want %q", order.Status, want)
+	}
+}
@@ -0,0 +1,113 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+)
+
+func openSQLiteRepository(t *testing.T) *repository.SQLOrderRepository {
+	t.Helper()
+	repo, err := repository.OpenSQLOrderRepository("sqlite", filepath.Join(t.TempDir(), "oms.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestSQLRepositoryOrderRoundTrip(t *testing.T) {
+	repo := openSQLiteRepository(t)
+
+	order := models.Order{ID: "o-1", Symbol: "AAPL", Side: "buy", Quantity: 10, Price: 150, Status: models.OrderStatusPending, CreatedAt: 1}
+	if _, err := repo.CreateOrder(order); err != nil {
+		t.Fatal(err)
+	}
+
+	order.Status = models.OrderStatusCanceled
+	if err := repo.UpdateOrder(&order); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.GetOrder("o-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.OrderStatusCanceled || got.Quantity != 10 {
+		t.Errorf("got %+v", got)
+	}
+
+	orders, err := repo.GetOrders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 1 {
+		t.Errorf("len(orders) = %d, want 1", len(orders))
+	}
+
+	if _, err := repo.GetOrder("missing"); err == nil {
+		t.Error("expected an error for a missing order")
+	}
+}
+
+func TestSQLRepositoryMigrateIsIdempotent(t *testing.T) {
+	repo := openSQLiteRepository(t)
+
+	if err := repo.Migrate(); err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+}
+
+func TestSQLRepositoryScalperOrderRoundTrip(t *testing.T) {
+	repo := openSQLiteRepository(t)
+
+	scalper := models.ScalperOrder{
+		ID:          "p-1",
+		Symbol:      "AAPL",
+		Quantity:    10,
+		ChildOrders: []models.Order{{ID: "c-1", Symbol: "AAPL", Quantity: 10}},
+	}
+	if _, err := repo.CreateScalperOrder(scalper); err != nil {
+		t.Fatal(err)
+	}
+
+	scalper.ChildOrders[0].Status = models.OrderStatusFilled
+	if err := repo.SaveScalperOrder(&scalper); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.GetScalperOrder("p-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.ChildOrders) != 1 || got.ChildOrders[0].Status != models.OrderStatusFilled {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestSQLRepositoryGetScalperOrders(t *testing.T) {
+	repo := openSQLiteRepository(t)
+
+	for i, id := range []string{"p-1", "p-2"} {
+		scalper := models.ScalperOrder{
+			ID:          id,
+			Symbol:      "AAPL",
+			Quantity:    10,
+			CreatedAt:   int64(i),
+			ChildOrders: []models.Order{{ID: id + "-c", Symbol: "AAPL", Quantity: 10}},
+		}
+		if err := repo.SaveScalperOrder(&scalper); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := repo.GetScalperOrders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "p-1" || len(got[1].ChildOrders) != 1 || got[1].ChildOrders[0].ID != "p-2-c" {
+		t.Errorf("got %+v", got)
+	}
+}
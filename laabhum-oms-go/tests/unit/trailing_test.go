@@ -0,0 +1,144 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Mukilan-T/laabhum-oms-go/models"
+	"github.com/Mukilan-T/laabhum-oms-go/repository"
+	"github.com/Mukilan-T/laabhum-oms-go/service"
+)
+
+func TestTrailingStopRatchetsAndTriggers(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+
+	if _, err := svc.CreateOrder(models.Order{Symbol: "INFY", Side: "sell", Quantity: 5, OrderType: models.OrderTypeTrailingStop, TrailAmount: 2, TrailPercent: 1}); err == nil {
+		t.Fatal("trailing stop with both trails accepted")
+	}
+
+	if _, err := svc.OnPrice("INFY", 100); err != nil {
+		t.Fatal(err)
+	}
+	order, err := svc.CreateOrder(models.Order{Symbol: "INFY", Side: "sell", Quantity: 5, OrderType: models.OrderTypeTrailingStop, TrailAmount: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.TriggerPrice != 98 {
+		t.Fatalf("trigger at creation = %g, want 98", order.TriggerPrice)
+	}
+
+	stored := func() models.Order {
+		t.Helper()
+		got, err := svc.GetOrders()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, o := range got {
+			if o.ID == order.ID {
+				return o
+			}
+		}
+		t.Fatal("order not found")
+		return models.Order{}
+	}
+
+	// The trigger follows the price up and stays put as it falls back.
+	for _, price := range []float64{103, 101.5} {
+		if triggered, err := svc.OnPrice("INFY", price); err != nil || len(triggered) != 0 {
+			t.Fatalf("OnPrice(%g) = %v, %v", price, triggered, err)
+		}
+	}
+	if got := stored(); got.TriggerPrice != 101 || got.Triggered {
+		t.Fatalf("after rally: trigger=%g triggered=%v, want 101 and false", got.TriggerPrice, got.Triggered)
+	}
+
+	triggered, err := svc.OnPrice("INFY", 100.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(triggered) != 1 || triggered[0].ID != order.ID {
+		t.Fatalf("triggered = %+v", triggered)
+	}
+	if got := stored(); !got.Triggered || got.Status != models.OrderStatusOpen || got.TriggerPrice != 101 {
+		t.Fatalf("after breach: %+v", got)
+	}
+}
+
+func TestBracketTrailingStopLeg(t *testing.T) {
+	svc := service.NewOMSService(repository.NewInMemoryOrderRepository())
+
+	parent, err := svc.CreateScalperOrder(models.ScalperOrder{
+		Symbol:      "TCS",
+		Quantity:    4,
+		ParentOrder: models.Order{Side: "buy", Price: 100},
+		Bracket:     &models.Bracket{TargetPrice: 120, StopLossPrice: 95, TrailPercent: 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _, stop := bracketLegs(t, svc, parent.ID)
+	if stop.OrderType != models.OrderTypeTrailingStop {
+		t.Fatalf("stop leg type = %s", stop.OrderType)
+	}
+
+	// Inactive legs do not trail.
+	if _, err := svc.OnPrice("TCS", 104); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, stop = bracketLegs(t, svc, parent.ID); stop.TriggerPrice != 95 {
+		t.Fatalf("inactive stop trigger = %g, want 95", stop.TriggerPrice)
+	}
+
+	if _, _, err := svc.RecordFill(models.Fill{OrderID: entry.ID, ExecutionID: "t-1", Quantity: 4, Price: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.OnPrice("TCS", 110); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, stop = bracketLegs(t, svc, parent.ID); stop.TriggerPrice != 104.5 {
+		t.Fatalf("active stop trigger = %g, want 104.5", stop.TriggerPrice)
+	}
+}
+
+func TestOnPriceWorksTheArmedStopsOfItsSymbol(t *testing.T) {
+	repo := repository.NewInMemoryOrderRepository()
+	before := service.NewOMSService(repo)
+	stop := func(svc *service.OMSService, symbol string) models.Order {
+		t.Helper()
+		order, err := svc.CreateOrder(models.Order{Symbol: symbol, Side: "sell", Quantity: 5, OrderType: models.OrderTypeStop, TriggerPrice: 95})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *order
+	}
+	existing := stop(before, "INFY")
+	canceled := stop(before, "INFY")
+	if err := before.CancelOrder(canceled.ID, canceled.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	other := stop(before, "TCS")
+
+	// A new service finds the stops already in the repository, and the ones
+	// created after it.
+	svc := service.NewOMSService(repo)
+	if triggered, err := svc.OnPrice("INFY", 100); err != nil || len(triggered) != 0 {
+		t.Fatalf("OnPrice(100) = %v, %v", triggered, err)
+	}
+	created := stop(svc, "INFY")
+	triggered, err := svc.OnPrice("INFY", 94)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]bool{}
+	for _, order := range triggered {
+		ids[order.ID] = true
+	}
+	if len(triggered) != 2 || !ids[existing.ID] || !ids[created.ID] {
+		t.Fatalf("triggered = %+v", triggered)
+	}
+	if triggered, err := svc.OnPrice("INFY", 93); err != nil || len(triggered) != 0 {
+		t.Errorf("triggered stops fired again: %v, %v", triggered, err)
+	}
+	if order, err := svc.GetOrder(other.ID); err != nil || order.Triggered {
+		t.Errorf("stop on another symbol = %+v, %v", order, err)
+	}
+}